@@ -13,7 +13,10 @@ func Func() {
 	redis := utils.NewRedisConnection()
 
 	logDisplay := windows.Win2(app)
-	cmdFlex, kvDisplay, _, flex := windows.Win3(app, logDisplay, redis)
+	redis.SetEventHandler(func(message string) {
+		logDisplay.Write([]byte(message + "\n"))
+	})
+	cmdFlex, kvDisplay, _, flex, views := windows.Win3(app, logDisplay, redis)
 
 	form := windows.ConnectionForm(app, logDisplay, redis, kvDisplay)
 	flex.AddItem(form, 40, 1, true).
@@ -27,7 +30,7 @@ func Func() {
 					flex.RemoveItem(form)
 					flex.RemoveItem(cmdFlex)
 					flex.RemoveItem(logDisplay)
-					flex.AddItem(windows.Win1(app, redis, kvDisplay), 40, 1, true).
+					flex.AddItem(windows.Win1(app, redis, kvDisplay, logDisplay, views), 40, 1, true).
 						AddItem(cmdFlex, 0, 2, false).
 						AddItem(logDisplay, 30, 1, false)
 				})
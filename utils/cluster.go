@@ -0,0 +1,222 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// clusterOptions builds the cluster client options, seeding it with the
+// configured host and any extra cluster node addresses.
+func clusterOptions(options ConnectOptions) (*redis.ClusterOptions, error) {
+	if options.DB != 0 {
+		return nil, fmt.Errorf("redis cluster only supports database 0")
+	}
+
+	addrs := options.ClusterAddrs
+	if options.Host != "" {
+		addrs = append([]string{fmt.Sprintf("%s:%s", options.Host, options.Port)}, addrs...)
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("cluster connections require at least one node address")
+	}
+
+	opts := &redis.ClusterOptions{
+		Addrs:                 addrs,
+		Username:              options.Username,
+		Password:              options.Password,
+		DialTimeout:           options.DialTimeout,
+		ReadTimeout:           options.ReadTimeout,
+		WriteTimeout:          options.WriteTimeout,
+		ContextTimeoutEnabled: true,
+	}
+
+	if options.TLS.active() {
+		tlsConfig, err := options.TLS.apply(nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS settings: %v", err)
+		}
+		opts.TLSConfig = tlsConfig
+	}
+
+	return opts, nil
+}
+
+// IsCluster reports whether the connection talks to a Redis Cluster.
+func (rc *RedisConnection) IsCluster() bool {
+	client := rc.current()
+	_, ok := client.(*redis.ClusterClient)
+	return ok
+}
+
+// forEachMaster runs fn against every master of a cluster, or once against
+// the single server otherwise. Cluster callbacks run concurrently.
+func forEachMaster(ctx context.Context, client redis.UniversalClient, fn func(ctx context.Context, client *redis.Client) error) error {
+	switch client := client.(type) {
+	case *redis.ClusterClient:
+		return client.ForEachMaster(ctx, fn)
+	case *redis.Client:
+		return fn(ctx, client)
+	}
+	return fmt.Errorf("not connected to Redis")
+}
+
+// KeyNode returns the address of the cluster master owning key, or an
+// empty string when not connected to a cluster.
+func (rc *RedisConnection) KeyNode(key string) (string, error) {
+	client := rc.current()
+	cluster, ok := client.(*redis.ClusterClient)
+	if !ok {
+		return "", nil
+	}
+
+	node, err := cluster.MasterForKey(rc.ctx, key)
+	if err != nil {
+		return "", err
+	}
+	return node.Options().Addr, nil
+}
+
+// dbSize returns the number of keys across all masters.
+func dbSize(ctx context.Context, client redis.UniversalClient) (int64, error) {
+	var mu sync.Mutex
+	var total int64
+
+	err := forEachMaster(ctx, client, func(ctx context.Context, client *redis.Client) error {
+		size, err := client.DBSize(ctx).Result()
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		total += size
+		mu.Unlock()
+		return nil
+	})
+	return total, err
+}
+
+// infoByMaster reads integer INFO fields from every master, keyed by the
+// master's address.
+func infoByMaster(ctx context.Context, client redis.UniversalClient, section string, fields ...string) (map[string]map[string]int64, error) {
+	var mu sync.Mutex
+	byMaster := make(map[string]map[string]int64)
+
+	err := forEachMaster(ctx, client, func(ctx context.Context, client *redis.Client) error {
+		info, err := client.Info(ctx, section).Result()
+		if err != nil {
+			return err
+		}
+
+		values := make(map[string]int64, len(fields))
+		for _, line := range strings.Split(info, "\n") {
+			for _, field := range fields {
+				var value int64
+				if strings.HasPrefix(line, field+":") {
+					fmt.Sscanf(strings.TrimSpace(line), field+":%d", &value)
+					values[field] = value
+				}
+			}
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		byMaster[client.Options().Addr] = values
+		return nil
+	})
+	return byMaster, err
+}
+
+// infoSum adds up integer INFO fields across all masters.
+func infoSum(ctx context.Context, client redis.UniversalClient, section string, fields ...string) (map[string]int64, error) {
+	byMaster, err := infoByMaster(ctx, client, section, fields...)
+	totals := make(map[string]int64, len(fields))
+	for _, values := range byMaster {
+		for field, value := range values {
+			totals[field] += value
+		}
+	}
+	return totals, err
+}
+
+// perHostSum adds up an INFO field that describes the machine rather than
+// the instance, such as total_system_memory, counting every host once
+// however many masters it runs.
+func perHostSum(byMaster map[string]map[string]int64, field string) int64 {
+	hosts := make(map[string]int64)
+	for addr, values := range byMaster {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			// Unix sockets are always on the local host
+			host = addr
+		}
+		if values[field] > hosts[host] {
+			hosts[host] = values[field]
+		}
+	}
+
+	var total int64
+	for _, value := range hosts {
+		total += value
+	}
+	return total
+}
+
+// executeOnAllMasters handles keyspace-wide commands that a cluster client
+// would otherwise send to a single node. It reports false when cmd needs no
+// fan-out and should be executed normally.
+func (rc *RedisConnection) executeOnAllMasters(client redis.UniversalClient, args []interface{}) (interface{}, bool, error) {
+	if _, ok := client.(*redis.ClusterClient); !ok {
+		return nil, false, nil
+	}
+
+	name := strings.ToLower(fmt.Sprint(args[0]))
+	switch name {
+	case "keys", "dbsize", "flushall", "flushdb":
+	default:
+		return nil, false, nil
+	}
+
+	var mu sync.Mutex
+	var keys []string
+	var size int64
+
+	err := forEachMaster(rc.ctx, client, func(ctx context.Context, client *redis.Client) error {
+		result, err := client.Do(ctx, args...).Result()
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch v := result.(type) {
+		case []interface{}:
+			for _, key := range v {
+				keys = append(keys, fmt.Sprint(key))
+			}
+		case int64:
+			size += v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, true, err
+	}
+
+	switch name {
+	case "keys":
+		sort.Strings(keys)
+		result := make([]interface{}, len(keys))
+		for i, key := range keys {
+			result[i] = key
+		}
+		return result, true, nil
+	case "dbsize":
+		return size, true, nil
+	}
+	return "OK", true, nil
+}
@@ -0,0 +1,17 @@
+package utils
+
+import "testing"
+
+func TestPerHostSum(t *testing.T) {
+	byMaster := map[string]map[string]int64{
+		"10.0.0.1:7000":   {"total_system_memory": 1000},
+		"10.0.0.1:7001":   {"total_system_memory": 1000},
+		"10.0.0.2:7000":   {"total_system_memory": 2000},
+		"/tmp/redis.sock": {"total_system_memory": 500},
+		"[::1]:7002":      {"total_system_memory": 300},
+		"10.0.0.3:7000":   {},
+	}
+	if got, want := perHostSum(byMaster, "total_system_memory"), int64(3800); got != want {
+		t.Errorf("perHostSum() = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DBInfo summarises one logical database from INFO keyspace.
+type DBInfo struct {
+	Index   int
+	Keys    int64
+	Expires int64
+}
+
+// DB returns the logical database every pooled connection is using.
+func (rc *RedisConnection) DB() int {
+	return rc.currentOptions().DB
+}
+
+// SelectDB switches the whole connection pool to database index by
+// reconnecting with the new index, so every pooled connection agrees.
+func (rc *RedisConnection) SelectDB(index int) error {
+	if !rc.IsConnected() {
+		return fmt.Errorf("not connected to Redis")
+	}
+	if rc.IsCluster() {
+		return fmt.Errorf("redis cluster only supports database 0")
+	}
+	if index < 0 {
+		return fmt.Errorf("invalid database index: %d", index)
+	}
+
+	options := rc.currentOptions()
+	options.DB = index
+	options.DBSet = true
+	return rc.ConnectWithOptions(options)
+}
+
+// DatabaseCount returns the number of logical databases configured on the
+// server, falling back to the default of 16 when CONFIG is not permitted.
+func (rc *RedisConnection) DatabaseCount() int {
+	client := rc.current()
+	if client == nil {
+		return 0
+	}
+
+	config, err := client.ConfigGet(rc.ctx, "databases").Result()
+	if err == nil {
+		if count, err := strconv.Atoi(config["databases"]); err == nil && count > 0 {
+			return count
+		}
+	}
+	return 16
+}
+
+// Databases returns key counts for every logical database. Databases that
+// INFO keyspace does not mention are empty.
+func (rc *RedisConnection) Databases() ([]DBInfo, error) {
+	client := rc.current()
+	if client == nil {
+		return nil, fmt.Errorf("not connected to Redis")
+	}
+
+	info, err := client.Info(rc.ctx, "keyspace").Result()
+	if err != nil {
+		return nil, fmt.Errorf("error getting keyspace info: %v", err)
+	}
+
+	dbs := make([]DBInfo, rc.DatabaseCount())
+	for i := range dbs {
+		dbs[i].Index = i
+	}
+
+	// Lines look like db0:keys=12,expires=3,avg_ttl=0
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "db") {
+			continue
+		}
+
+		var db DBInfo
+		if _, err := fmt.Sscanf(line, "db%d:keys=%d,expires=%d", &db.Index, &db.Keys, &db.Expires); err != nil {
+			continue
+		}
+		if db.Index >= len(dbs) {
+			continue
+		}
+		dbs[db.Index] = db
+	}
+
+	return dbs, nil
+}
@@ -0,0 +1,30 @@
+package utils
+
+import "testing"
+
+func TestStandaloneOptionsDB(t *testing.T) {
+	tests := []struct {
+		name    string
+		options ConnectOptions
+		want    int
+	}{
+		{"url database", ConnectOptions{Host: "redis://localhost:6379/5"}, 5},
+		{"url database wins over a saved one", ConnectOptions{Host: "redis://localhost:6379/5", DB: 2}, 5},
+		{"saved database fills in the url", ConnectOptions{Host: "redis://localhost:6379", DB: 2}, 2},
+		{"selected database wins over the url", ConnectOptions{Host: "redis://localhost:6379/5", DB: 3, DBSet: true}, 3},
+		{"selecting database 0 wins over the url", ConnectOptions{Host: "redis://localhost:6379/5", DB: 0, DBSet: true}, 0},
+		{"host and port", ConnectOptions{Host: "localhost", Port: "6379", DB: 4}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := standaloneOptions(tt.options)
+			if err != nil {
+				t.Fatalf("standaloneOptions returned error: %v", err)
+			}
+			if opts.DB != tt.want {
+				t.Errorf("DB = %d, want %d", opts.DB, tt.want)
+			}
+		})
+	}
+}
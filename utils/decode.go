@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxDecodeSteps bounds how many encodings Decode peels off a value.
+const maxDecodeSteps = 4
+
+// MaxDecompressed caps the size of a gunzipped value, guarding against
+// compression bombs.
+const MaxDecompressed = 16 << 20
+
+// Decoded is a value with the recognised encodings removed.
+type Decoded struct {
+	Steps  []string    // encodings removed, outermost first, e.g. base64, gzip
+	Data   []byte      // the payload left after the last step
+	Format string      // "json" or "msgpack" when the payload is structured
+	Value  interface{} // the parsed payload for structured formats
+	// Truncated is set when a gzip layer held more than MaxDecompressed
+	// bytes, of which Data only has the first
+	Truncated bool
+}
+
+// Decode detects and removes gzip and base64 layers from raw, then parses
+// the payload when it is a JSON or MessagePack document. Detection is
+// conservative: base64 is only peeled off when what is underneath is
+// recognisable, so ordinary strings are left alone.
+func Decode(raw []byte) Decoded {
+	d := Decoded{Data: raw}
+
+	for len(d.Steps) < maxDecodeSteps {
+		if unzipped, truncated, ok := gunzip(d.Data); ok {
+			d.Steps = append(d.Steps, "gzip")
+			d.Data = unzipped
+			if truncated {
+				// Whatever is underneath is cut short, so stop here
+				d.Truncated = true
+				return d
+			}
+			continue
+		}
+		if decoded, ok := unbase64(d.Data); ok {
+			d.Steps = append(d.Steps, "base64")
+			d.Data = decoded
+			continue
+		}
+		break
+	}
+
+	if value, ok := parseJSON(d.Data); ok {
+		d.Format, d.Value = "json", value
+	} else if value, ok := parseMsgpack(d.Data); ok {
+		d.Format, d.Value = "msgpack", value
+	}
+	return d
+}
+
+// parseJSON accepts data when it is exactly one JSON value, scalars
+// included.
+func parseJSON(data []byte) (interface{}, bool) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return nil, false
+	}
+	return value, true
+}
+
+// parseMsgpack accepts data only when it is a MessagePack map or array, as
+// nearly any byte string is some valid scalar.
+func parseMsgpack(data []byte) (interface{}, bool) {
+	if len(data) < 2 {
+		return nil, false
+	}
+	c := data[0]
+	isContainer := (c >= 0x80 && c <= 0x9f) || (c >= 0xdc && c <= 0xdf)
+	if !isContainer {
+		return nil, false
+	}
+
+	value, err := DecodeMsgpack(data)
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// gunzip decompresses data when it is gzip, keeping at most
+// MaxDecompressed bytes and reporting whether there was more.
+func gunzip(data []byte) (unzipped []byte, truncated bool, ok bool) {
+	if len(data) < 18 || data[0] != 0x1f || data[1] != 0x8b {
+		return nil, false, false
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, false, false
+	}
+	defer reader.Close()
+
+	// One byte over the cap tells a truncated stream from one that fits
+	unzipped, err = io.ReadAll(io.LimitReader(reader, MaxDecompressed+1))
+	if err != nil {
+		return nil, false, false
+	}
+	if len(unzipped) > MaxDecompressed {
+		return unzipped[:MaxDecompressed], true, true
+	}
+	return unzipped, false, true
+}
+
+// unbase64 decodes standard or URL-safe base64, padded or not, keeping the
+// result only when it is gzip, JSON, MessagePack or readable text.
+func unbase64(data []byte) ([]byte, bool) {
+	text := string(bytes.TrimSpace(data))
+	if len(text) < 8 {
+		return nil, false
+	}
+
+	for _, encoding := range []*base64.Encoding{
+		base64.StdEncoding, base64.RawStdEncoding,
+		base64.URLEncoding, base64.RawURLEncoding,
+	} {
+		decoded, err := encoding.DecodeString(text)
+		if err != nil {
+			continue
+		}
+
+		if _, _, ok := gunzip(decoded); ok {
+			return decoded, true
+		}
+		if _, ok := parseJSON(decoded); ok {
+			return decoded, true
+		}
+		if _, ok := parseMsgpack(decoded); ok {
+			return decoded, true
+		}
+		if IsReadable(decoded) {
+			return decoded, true
+		}
+		return nil, false
+	}
+	return nil, false
+}
+
+// IsReadable reports whether data is valid UTF-8 made of printable
+// characters and common whitespace.
+func IsReadable(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && r != '\n' && r != '\r' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
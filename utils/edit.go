@@ -0,0 +1,337 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MaxEditLength caps how many elements a collection may have to be opened
+// in an editor, as the whole value is read and rewritten.
+const MaxEditLength = 100000
+
+// ErrKeyChanged is returned by SaveEdit when the key was modified after it
+// was opened for editing.
+var ErrKeyChanged = errors.New("the key was modified since it was opened")
+
+// EditSession is a value opened for editing as text. Strings are edited
+// as-is, hashes and sorted sets as JSON objects (member to score for sorted
+// sets) and lists and sets as JSON arrays.
+type EditSession struct {
+	Key  string
+	Type string
+	Text []byte // the text to put in the editor
+
+	snapshot []byte // the value as read, compared again before saving
+	json     bool   // a string holding a JSON document, which must stay valid
+	compact  bool   // the JSON string was stored without indentation
+}
+
+// Extension suggests a file extension for the editor's syntax highlighting.
+func (s *EditSession) Extension() string {
+	if s.Type != "string" || s.json {
+		return ".json"
+	}
+	return ".txt"
+}
+
+// OpenEdit reads key in full and renders it as text for editing.
+func (rc *RedisConnection) OpenEdit(key string) (*EditSession, error) {
+	client := rc.current()
+	if client == nil {
+		return nil, fmt.Errorf("not connected to Redis")
+	}
+
+	kind, snapshot, err := editSnapshot(rc.ctx, client, key)
+	if errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("key '%s' does not exist", key)
+	} else if err != nil {
+		return nil, err
+	}
+	session := &EditSession{Key: key, Type: kind, snapshot: snapshot}
+
+	if kind == "string" {
+		if !IsReadable(snapshot) {
+			return nil, fmt.Errorf("key '%s' holds binary data, which cannot be edited as text", key)
+		}
+		if _, ok := parseJSON(snapshot); ok {
+			session.json = true
+			var compacted bytes.Buffer
+			session.compact = json.Compact(&compacted, snapshot) == nil && bytes.Equal(compacted.Bytes(), snapshot)
+		}
+		if !session.json {
+			session.Text = snapshot
+			return session, nil
+		}
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, snapshot, "", "  "); err != nil {
+		return nil, err
+	}
+	indented.WriteByte('\n')
+	session.Text = indented.Bytes()
+	return session, nil
+}
+
+// Validate reports whether edited can be saved, describing the first
+// problem found.
+func (s *EditSession) Validate(edited []byte) error {
+	_, err := s.parse(edited)
+	return err
+}
+
+// SaveEdit writes edited back to the key, keeping its TTL. The key is
+// WATCHed and read again first: if it no longer matches what was opened,
+// nothing is written and ErrKeyChanged is returned.
+func (rc *RedisConnection) SaveEdit(s *EditSession, edited []byte) error {
+	client := rc.current()
+	if client == nil {
+		return fmt.Errorf("not connected to Redis")
+	}
+
+	value, err := s.parse(edited)
+	if err != nil {
+		return err
+	}
+
+	ctx := rc.ctx
+	err = client.Watch(ctx, func(tx *redis.Tx) error {
+		kind, current, err := editSnapshot(ctx, tx, s.Key)
+		if errors.Is(err, redis.Nil) {
+			return ErrKeyChanged
+		} else if err != nil {
+			return err
+		}
+		if kind != s.Type || !bytes.Equal(current, s.snapshot) {
+			return ErrKeyChanged
+		}
+
+		ttl, err := tx.PTTL(ctx, s.Key).Result()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if s.Type == "string" {
+				pipe.Set(ctx, s.Key, value[0], 0)
+			} else {
+				pipe.Del(ctx, s.Key)
+				switch s.Type {
+				case "hash":
+					pipe.HSet(ctx, s.Key, value...)
+				case "list":
+					pipe.RPush(ctx, s.Key, value...)
+				case "set":
+					pipe.SAdd(ctx, s.Key, value...)
+				case "zset":
+					members := make([]redis.Z, 0, len(value)/2)
+					for i := 0; i+1 < len(value); i += 2 {
+						members = append(members, redis.Z{Member: value[i], Score: value[i+1].(float64)})
+					}
+					pipe.ZAdd(ctx, s.Key, members...)
+				}
+			}
+			if ttl > 0 {
+				pipe.PExpire(ctx, s.Key, ttl)
+			}
+			return nil
+		})
+		return err
+	}, s.Key)
+
+	if errors.Is(err, redis.TxFailedErr) {
+		return ErrKeyChanged
+	}
+	return err
+}
+
+// editSnapshot reads key in full, as raw bytes for strings and as JSON for
+// collections. Hash fields and set members are sorted so two reads of the
+// same value compare equal.
+func editSnapshot(ctx context.Context, c redis.Cmdable, key string) (string, []byte, error) {
+	kind, err := c.Type(ctx, key).Result()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var length int64
+	switch kind {
+	case "string":
+		value, err := c.Get(ctx, key).Result()
+		return kind, []byte(value), err
+	case "hash":
+		length, err = c.HLen(ctx, key).Result()
+	case "list":
+		length, err = c.LLen(ctx, key).Result()
+	case "set":
+		length, err = c.SCard(ctx, key).Result()
+	case "zset":
+		length, err = c.ZCard(ctx, key).Result()
+	case "none":
+		return kind, nil, redis.Nil
+	default:
+		return kind, nil, fmt.Errorf("%s values cannot be edited as text", kind)
+	}
+	if err != nil {
+		return kind, nil, err
+	}
+	if length > MaxEditLength {
+		return kind, nil, fmt.Errorf("key '%s' has %d elements, more than the %d that can be edited at once", key, length, MaxEditLength)
+	}
+
+	var data interface{}
+	switch kind {
+	case "hash":
+		data, err = c.HGetAll(ctx, key).Result()
+	case "list":
+		data, err = c.LRange(ctx, key, 0, -1).Result()
+	case "set":
+		var members []string
+		members, err = c.SMembers(ctx, key).Result()
+		sort.Strings(members)
+		data = members
+	case "zset":
+		var members []redis.Z
+		members, err = c.ZRangeWithScores(ctx, key, 0, -1).Result()
+		scores := make(map[string]float64, len(members))
+		for _, m := range members {
+			scores[fmt.Sprint(m.Member)] = m.Score
+		}
+		data = scores
+	}
+	if err != nil {
+		return kind, nil, err
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return kind, nil, fmt.Errorf("key '%s' cannot be edited as JSON: %v", key, err)
+	}
+	return kind, encoded, nil
+}
+
+// parse validates edited text and converts it to command arguments: the
+// new string, field/value pairs for hashes, elements for lists and sets,
+// and member/score pairs for sorted sets.
+func (s *EditSession) parse(edited []byte) ([]interface{}, error) {
+	if s.Type == "string" {
+		if !s.json {
+			// Editors add a final newline the original may not have had
+			if !bytes.HasSuffix(s.snapshot, []byte("\n")) {
+				edited = bytes.TrimSuffix(bytes.TrimSuffix(edited, []byte("\n")), []byte("\r"))
+			}
+			return []interface{}{string(edited)}, nil
+		}
+
+		trimmed := bytes.TrimSpace(edited)
+		if err := checkJSON(trimmed); err != nil {
+			return nil, err
+		}
+		if s.compact {
+			var compacted bytes.Buffer
+			json.Compact(&compacted, trimmed)
+			trimmed = compacted.Bytes()
+		}
+		return []interface{}{string(trimmed)}, nil
+	}
+
+	if err := checkJSON(edited); err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(edited))
+	decoder.UseNumber()
+
+	var args []interface{}
+	switch s.Type {
+	case "hash", "zset":
+		var object map[string]interface{}
+		if err := decoder.Decode(&object); err != nil {
+			return nil, fmt.Errorf("a %s is edited as a JSON object: %v", s.Type, err)
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if s.Type == "hash" {
+				value, err := editScalar(object[name])
+				if err != nil {
+					return nil, fmt.Errorf("field %q: %v", name, err)
+				}
+				args = append(args, name, value)
+				continue
+			}
+			number, ok := object[name].(json.Number)
+			if !ok {
+				return nil, fmt.Errorf("member %q: the score must be a number", name)
+			}
+			score, err := strconv.ParseFloat(number.String(), 64)
+			if err != nil {
+				return nil, fmt.Errorf("member %q: invalid score %s", name, number)
+			}
+			args = append(args, name, score)
+		}
+
+	case "list", "set":
+		var array []interface{}
+		if err := decoder.Decode(&array); err != nil {
+			return nil, fmt.Errorf("a %s is edited as a JSON array: %v", s.Type, err)
+		}
+		for i, item := range array {
+			value, err := editScalar(item)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %v", i, err)
+			}
+			args = append(args, value)
+		}
+	}
+
+	// Redis deletes empty collections, which an edit should not do silently
+	if len(args) == 0 {
+		return nil, fmt.Errorf("an empty %s would delete the key, use del instead", s.Type)
+	}
+	return args, nil
+}
+
+// editScalar converts a JSON scalar to the string stored in Redis
+func editScalar(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	return "", fmt.Errorf("must be a string, number or boolean")
+}
+
+// checkJSON reports a syntax error in data with its line and column
+func checkJSON(data []byte) error {
+	var value interface{}
+	err := json.Unmarshal(data, &value)
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		// Type errors are reported by the caller, which knows the expected shape
+		return nil
+	}
+
+	line, column := 1, 1
+	for _, c := range data[:syntaxErr.Offset] {
+		if c == '\n' {
+			line, column = line+1, 1
+		} else {
+			column++
+		}
+	}
+	return fmt.Errorf("invalid JSON at line %d, column %d: %v", line, column, err)
+}
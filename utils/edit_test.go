@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEditSessionParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		session EditSession
+		edited  string
+		want    []interface{}
+	}{
+		{"text keeps its missing newline", EditSession{Type: "string", snapshot: []byte("hi")}, "hello\n", []interface{}{"hello"}},
+		{"text keeps its newline", EditSession{Type: "string", snapshot: []byte("hi\n")}, "hello\n", []interface{}{"hello\n"}},
+		{"compact json stays compact", EditSession{Type: "string", json: true, compact: true}, "{\n  \"a\": 1\n}\n", []interface{}{`{"a":1}`}},
+		{"hash", EditSession{Type: "hash"}, `{"b": "x", "a": 2, "c": true}`, []interface{}{"a", "2", "b", "x", "c", "true"}},
+		{"list", EditSession{Type: "list"}, `["x", 1]`, []interface{}{"x", "1"}},
+		{"zset", EditSession{Type: "zset"}, `{"m": 1.5}`, []interface{}{"m", 1.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.session.parse([]byte(tt.edited))
+			if err != nil {
+				t.Fatalf("parse(%q) returned error: %v", tt.edited, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parse(%q) = %#v, want %#v", tt.edited, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditSessionParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		session EditSession
+		edited  string
+		want    string
+	}{
+		{"broken json string", EditSession{Type: "string", json: true}, "{\n  \"a\": 1,\n}", "line 3"},
+		{"hash as array", EditSession{Type: "hash"}, `["a"]`, "JSON object"},
+		{"nested hash value", EditSession{Type: "hash"}, `{"a": {"b": 1}}`, `field "a"`},
+		{"empty list", EditSession{Type: "list"}, `[]`, "delete the key"},
+		{"zset score", EditSession{Type: "zset"}, `{"m": "high"}`, "score must be a number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.session.parse([]byte(tt.edited))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parse(%q) error = %v, want one mentioning %q", tt.edited, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// HashPageSize is the COUNT hint used when paging a hash with HSCAN.
+const HashPageSize = 100
+
+// HashPage is one HSCAN page of a hash.
+type HashPage struct {
+	Fields []HashField
+	Next   uint64 // cursor of the following page, 0 after the last one
+	Length int64  // HLEN of the whole hash
+
+	// TTLs holds the remaining time to live of each field (-1 without one),
+	// read with HPTTL. It is nil when the server has no field expiration.
+	TTLs []time.Duration
+}
+
+// ScanHash reads the page of key's fields that starts at cursor, keeping
+// only fields matching the glob match (all fields when empty).
+func (rc *RedisConnection) ScanHash(key string, cursor uint64, match string) (*HashPage, error) {
+	client := rc.current()
+	if client == nil {
+		return nil, fmt.Errorf("not connected to Redis")
+	}
+	if match == "" {
+		match = "*"
+	}
+
+	ctx := rc.ctx
+	length, err := client.HLen(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	pairs, next, err := client.HScan(ctx, key, cursor, match, HashPageSize).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	// HSCAN may repeat a field, keep its first appearance
+	page := &HashPage{Next: next, Length: length}
+	seen := make(map[string]struct{}, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if _, ok := seen[pairs[i]]; ok {
+			continue
+		}
+		seen[pairs[i]] = struct{}{}
+		page.Fields = append(page.Fields, HashField{Field: pairs[i], Value: pairs[i+1]})
+	}
+	sort.Slice(page.Fields, func(i, j int) bool {
+		return page.Fields[i].Field < page.Fields[j].Field
+	})
+
+	if len(page.Fields) > 0 {
+		page.TTLs, err = hashFieldTTLs(ctx, client, key, page.Fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// hashFieldTTLs reads the TTL of every field with HPTTL, returning nil
+// when the server does not support field expiration (before Redis 7.4).
+func hashFieldTTLs(ctx context.Context, c redis.Cmdable, key string, fields []HashField) ([]time.Duration, error) {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Field
+	}
+
+	ttls, err := c.HPTTL(ctx, key, names...).Result()
+	if isUnknownCommand(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	durations := make([]time.Duration, len(fields))
+	for i := range durations {
+		durations[i] = -1
+		if i < len(ttls) && ttls[i] >= 0 {
+			durations[i] = time.Duration(ttls[i]) * time.Millisecond
+		}
+	}
+	return durations, nil
+}
+
+// SetHashField sets the value of an existing or new field. HSET clears the
+// expiration of a field it overwrites, so a field TTL is put back.
+func (rc *RedisConnection) SetHashField(key, field, value string) error {
+	client := rc.current()
+	if client == nil {
+		return fmt.Errorf("not connected to Redis")
+	}
+
+	ctx := rc.ctx
+	ttls, err := hashFieldTTLs(ctx, client, key, []HashField{{Field: field}})
+	if err != nil {
+		return err
+	}
+
+	_, err = client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, field, value)
+		if len(ttls) == 1 && ttls[0] > 0 {
+			pipe.HPExpire(ctx, key, ttls[0], field)
+		}
+		return nil
+	})
+	return err
+}
+
+// AddHashField adds a field only if it does not exist yet, reporting
+// whether it was added.
+func (rc *RedisConnection) AddHashField(key, field, value string) (bool, error) {
+	client := rc.current()
+	if client == nil {
+		return false, fmt.Errorf("not connected to Redis")
+	}
+
+	return client.HSetNX(rc.ctx, key, field, value).Result()
+}
+
+// DeleteHashField removes a field. Redis deletes the key with its last field.
+func (rc *RedisConnection) DeleteHashField(key, field string) error {
+	client := rc.current()
+	if client == nil {
+		return fmt.Errorf("not connected to Redis")
+	}
+
+	removed, err := client.HDel(rc.ctx, key, field).Result()
+	if err == nil && removed == 0 {
+		return fmt.Errorf("field '%s' does not exist", field)
+	}
+	return err
+}
+
+// ExpireHashField sets the TTL of a single field with HPEXPIRE, or removes
+// it with HPERSIST when ttl is zero. It needs Redis 7.4 or later.
+func (rc *RedisConnection) ExpireHashField(key, field string, ttl time.Duration) error {
+	client := rc.current()
+	if client == nil {
+		return fmt.Errorf("not connected to Redis")
+	}
+
+	var codes []int64
+	var err error
+	if ttl <= 0 {
+		codes, err = client.HPersist(rc.ctx, key, field).Result()
+	} else {
+		codes, err = client.HPExpire(rc.ctx, key, ttl, field).Result()
+	}
+	if isUnknownCommand(err) {
+		return fmt.Errorf("this server does not support field expiration (Redis 7.4 or later is needed)")
+	} else if err != nil {
+		return err
+	}
+	if len(codes) == 1 && codes[0] == -2 {
+		return fmt.Errorf("field '%s' does not exist", field)
+	}
+	return nil
+}
+
+// isUnknownCommand reports whether err means the server lacks a command
+func isUnknownCommand(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "unknown command")
+}
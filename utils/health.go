@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ConnectionState describes the health of the connection to Redis.
+type ConnectionState int
+
+const (
+	StateDisconnected ConnectionState = iota
+	StateConnected
+	StateReconnecting
+	StateDown
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateDown:
+		return "down"
+	}
+	return "disconnected"
+}
+
+const (
+	healthCheckInterval = 2 * time.Second
+	reconnectMinBackoff = 500 * time.Millisecond
+	reconnectMaxBackoff = 30 * time.Second
+	// attempts after which a reconnecting connection is reported as down
+	reconnectAttemptsBeforeDown = 5
+)
+
+// healthState is shared between the UI and the health monitor goroutine.
+type healthState struct {
+	mu        sync.RWMutex
+	state     ConnectionState
+	listeners []func(ConnectionState)
+}
+
+// State returns the current connection state.
+func (rc *RedisConnection) State() ConnectionState {
+	rc.health.mu.RLock()
+	defer rc.health.mu.RUnlock()
+	return rc.health.state
+}
+
+// OnStateChange registers a callback invoked whenever the connection state
+// changes. Callbacks run on the health monitor goroutine.
+func (rc *RedisConnection) OnStateChange(fn func(ConnectionState)) {
+	rc.health.mu.Lock()
+	defer rc.health.mu.Unlock()
+	rc.health.listeners = append(rc.health.listeners, fn)
+}
+
+func (rc *RedisConnection) setState(state ConnectionState) {
+	rc.health.mu.Lock()
+	if rc.health.state == state {
+		rc.health.mu.Unlock()
+		return
+	}
+	rc.health.state = state
+	listeners := append([]func(ConnectionState){}, rc.health.listeners...)
+	rc.health.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(state)
+	}
+}
+
+// monitorHealth pings client periodically and, once a ping fails, keeps
+// probing with exponential backoff until the server answers again. The
+// client's pool re-dials on its own, so a successful ping means the
+// connection has been re-established. A connection through an SSH tunnel
+// cannot recover that way once the tunnel dies, so it is dialled again from
+// options, tunnel included, on every attempt after the first.
+func (rc *RedisConnection) monitorHealth(ctx context.Context, client redis.UniversalClient, options ConnectOptions) {
+	wait := healthCheckInterval
+	backoff := reconnectMinBackoff
+	attempts := 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, healthCheckInterval)
+		err := client.Ping(pingCtx).Err()
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err == nil {
+			if attempts > 0 {
+				rc.emit("[green]Connection restored after %d attempt(s)[white]", attempts)
+			}
+			rc.setState(StateConnected)
+			attempts, backoff, wait = 0, reconnectMinBackoff, healthCheckInterval
+			continue
+		}
+
+		attempts++
+		if attempts == 1 {
+			rc.emit("[red]Connection lost:[white] %v", err)
+		}
+		if attempts > 1 && options.SSH.active() {
+			if d, err := rc.dial(options); err == nil {
+				// The new client gets a monitor of its own
+				if rc.install(d, client) {
+					rc.emit("[green]Connection restored after %d attempt(s), SSH tunnel reopened[white]", attempts)
+				}
+				return
+			}
+		}
+		if attempts >= reconnectAttemptsBeforeDown {
+			rc.setState(StateDown)
+		} else {
+			rc.setState(StateReconnecting)
+		}
+
+		wait = backoff
+		backoff *= 2
+		if backoff > reconnectMaxBackoff {
+			backoff = reconnectMaxBackoff
+		}
+	}
+}
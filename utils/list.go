@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ListPageSize is how many elements of a list are read at once.
+const ListPageSize = 100
+
+// ListPage is a run of list elements starting at index Start.
+type ListPage struct {
+	Items  []string
+	Start  int64
+	Length int64 // LLEN of the whole list
+}
+
+// ListRange reads the page of key's elements starting at index start.
+func (rc *RedisConnection) ListRange(key string, start int64) (*ListPage, error) {
+	client := rc.current()
+	if client == nil {
+		return nil, fmt.Errorf("not connected to Redis")
+	}
+
+	length, err := client.LLen(rc.ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	items, err := client.LRange(rc.ctx, key, start, start+ListPageSize-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	return &ListPage{Items: items, Start: start, Length: length}, nil
+}
+
+// PushList adds value to the head (LPUSH) or tail (RPUSH) of a list,
+// returning the new length.
+func (rc *RedisConnection) PushList(key, value string, head bool) (int64, error) {
+	client := rc.current()
+	if client == nil {
+		return 0, fmt.Errorf("not connected to Redis")
+	}
+
+	if head {
+		return client.LPush(rc.ctx, key, value).Result()
+	}
+	return client.RPush(rc.ctx, key, value).Result()
+}
+
+// PopList removes and returns the head (LPOP) or tail (RPOP) element.
+func (rc *RedisConnection) PopList(key string, head bool) (string, error) {
+	client := rc.current()
+	if client == nil {
+		return "", fmt.Errorf("not connected to Redis")
+	}
+
+	var value string
+	var err error
+	if head {
+		value, err = client.LPop(rc.ctx, key).Result()
+	} else {
+		value, err = client.RPop(rc.ctx, key).Result()
+	}
+	if err == redis.Nil {
+		return "", fmt.Errorf("the list is empty")
+	}
+	return value, err
+}
+
+// InsertList inserts value before or after the first element equal to
+// pivot (LINSERT), returning the new length.
+func (rc *RedisConnection) InsertList(key, pivot, value string, before bool) (int64, error) {
+	client := rc.current()
+	if client == nil {
+		return 0, fmt.Errorf("not connected to Redis")
+	}
+
+	var length int64
+	var err error
+	if before {
+		length, err = client.LInsertBefore(rc.ctx, key, pivot, value).Result()
+	} else {
+		length, err = client.LInsertAfter(rc.ctx, key, pivot, value).Result()
+	}
+	if err == nil && length < 0 {
+		return 0, fmt.Errorf("the element to insert next to no longer exists")
+	}
+	return length, err
+}
+
+// SetListItem replaces the element at index with LSET, provided it still
+// holds old: the list is WATCHed so a shifted list is not overwritten at
+// the wrong position, returning ErrKeyChanged instead.
+func (rc *RedisConnection) SetListItem(key string, index int64, old, value string) error {
+	client := rc.current()
+	if client == nil {
+		return fmt.Errorf("not connected to Redis")
+	}
+
+	ctx := rc.ctx
+	err := client.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := tx.LIndex(ctx, key, index).Result()
+		if err == redis.Nil || (err == nil && current != old) {
+			return ErrKeyChanged
+		} else if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.LSet(ctx, key, index, value)
+			return nil
+		})
+		return err
+	}, key)
+
+	if err == redis.TxFailedErr {
+		return ErrKeyChanged
+	}
+	return err
+}
+
+// RemoveListItems removes occurrences of value with LREM: the first count
+// from the head, the last -count from the tail, or all of them for 0. It
+// returns how many were removed.
+func (rc *RedisConnection) RemoveListItems(key string, count int64, value string) (int64, error) {
+	client := rc.current()
+	if client == nil {
+		return 0, fmt.Errorf("not connected to Redis")
+	}
+
+	return client.LRem(rc.ctx, key, count, value).Result()
+}
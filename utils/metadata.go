@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MetadataBatchSize is how many keys go into one pipeline when fetching
+// key metadata, bounding the size of each round trip.
+const MetadataBatchSize = 500
+
+// previewLength is how many bytes of a string value are fetched for the
+// key table preview.
+const previewLength = 64
+
+// KeyMeta is the per-key information shown in the key table.
+type KeyMeta struct {
+	Key      string
+	Type     string        // "none" when the key vanished between SCAN and the fetch
+	Encoding string        // OBJECT ENCODING, such as listpack, hashtable or intset
+	TTL      time.Duration // -1 when the key has no expiry, -2 when it does not exist
+	Idle     time.Duration // OBJECT IDLETIME, -1 when unavailable (LFU eviction policies)
+	Memory   int64         // MEMORY USAGE in bytes, 0 when unavailable
+	Length   int64         // STRLEN for strings, element count for other types
+	Preview  string        // leading bytes of a string value, empty for other types
+	Err      error         // first error met while fetching this key, if any
+}
+
+// Missing reports whether the key no longer exists.
+func (m KeyMeta) Missing() bool {
+	return m.Type == "none"
+}
+
+// GetKeysMeta fetches TYPE, OBJECT ENCODING, OBJECT IDLETIME, PTTL, MEMORY
+// USAGE, the length and a short value preview for keys, pipelining them in
+// batches of MetadataBatchSize so a page of keys costs two round trips per
+// batch rather than several per key.
+// Results are in the same order as keys. In a cluster the pipeline is split
+// per node by the client. Every batch runs on the client that was live when
+// the call started; if it is replaced meanwhile the remaining batches are
+// not sent.
+func (rc *RedisConnection) GetKeysMeta(keys []string) ([]KeyMeta, error) {
+	client := rc.current()
+	if client == nil {
+		return nil, fmt.Errorf("not connected to Redis")
+	}
+
+	metas := make([]KeyMeta, 0, len(keys))
+	for start := 0; start < len(keys); start += MetadataBatchSize {
+		end := start + MetadataBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		if rc.current() != client {
+			return metas, errClientReplaced
+		}
+		batch, err := rc.keysMetaBatch(client, keys[start:end])
+		if err != nil {
+			return metas, err
+		}
+		metas = append(metas, batch...)
+	}
+	return metas, nil
+}
+
+// keysMetaBatch runs two pipelines for keys: one for what every type
+// supports, then one for the length and preview, whose commands depend on
+// the type. Per-command failures are only recorded on the key; a failure
+// of the connection is returned.
+func (rc *RedisConnection) keysMetaBatch(client redis.UniversalClient, keys []string) ([]KeyMeta, error) {
+	type keyCmds struct {
+		typ      *redis.StatusCmd
+		encoding *redis.StringCmd
+		idle     *redis.DurationCmd
+		ttl      *redis.DurationCmd
+		memory   *redis.IntCmd
+	}
+
+	cmds := make([]keyCmds, len(keys))
+	_, err := client.Pipelined(rc.ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = keyCmds{
+				typ:      pipe.Type(rc.ctx, key),
+				encoding: pipe.ObjectEncoding(rc.ctx, key),
+				idle:     pipe.ObjectIdleTime(rc.ctx, key),
+				ttl:      pipe.PTTL(rc.ctx, key),
+				memory:   pipe.MemoryUsage(rc.ctx, key),
+			}
+		}
+		return nil
+	})
+	// Exec reports the first failed command, which is normal here; only
+	// connection level errors mean the results are unusable
+	if err != nil && isConnectionError(err) {
+		return nil, err
+	}
+
+	metas := make([]KeyMeta, len(keys))
+	for i, key := range keys {
+		c := cmds[i]
+		meta := KeyMeta{Key: key, TTL: -1, Idle: -1}
+
+		meta.Type, err = c.typ.Result()
+		if err != nil {
+			meta.Err = err
+		}
+		meta.Encoding, _ = c.encoding.Result()
+		if idle, err := c.idle.Result(); err == nil {
+			meta.Idle = idle
+		}
+		if ttl, err := c.ttl.Result(); err == nil {
+			meta.TTL = ttl
+		}
+		// MEMORY USAGE returns nil for keys that disappeared
+		if memory, err := c.memory.Result(); err == nil {
+			meta.Memory = memory
+		} else if err != redis.Nil && meta.Err == nil {
+			meta.Err = err
+		}
+
+		metas[i] = meta
+	}
+
+	// Second round trip: the length command to use depends on the type
+	lengths := make([]*redis.IntCmd, len(keys))
+	previews := make([]*redis.StringCmd, len(keys))
+	_, err = client.Pipelined(rc.ctx, func(pipe redis.Pipeliner) error {
+		for i, meta := range metas {
+			switch meta.Type {
+			case "string":
+				lengths[i] = pipe.StrLen(rc.ctx, meta.Key)
+				previews[i] = pipe.GetRange(rc.ctx, meta.Key, 0, previewLength-1)
+			case "hash":
+				lengths[i] = pipe.HLen(rc.ctx, meta.Key)
+			case "list":
+				lengths[i] = pipe.LLen(rc.ctx, meta.Key)
+			case "set":
+				lengths[i] = pipe.SCard(rc.ctx, meta.Key)
+			case "zset":
+				lengths[i] = pipe.ZCard(rc.ctx, meta.Key)
+			case "stream":
+				lengths[i] = pipe.XLen(rc.ctx, meta.Key)
+			}
+		}
+		return nil
+	})
+	if err != nil && isConnectionError(err) {
+		return nil, err
+	}
+
+	for i := range metas {
+		if lengths[i] != nil {
+			metas[i].Length, _ = lengths[i].Result()
+		}
+		if previews[i] != nil {
+			metas[i].Preview, _ = previews[i].Result()
+		}
+	}
+	return metas, nil
+}
+
+// isConnectionError reports whether err came from the connection rather
+// than from Redis rejecting a command.
+func isConnectionError(err error) bool {
+	if err == nil || err == redis.Nil {
+		return false
+	}
+	_, isRedisErr := err.(redis.Error)
+	return !isRedisErr
+}
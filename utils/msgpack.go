@@ -0,0 +1,247 @@
+package utils
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"time"
+)
+
+// DecodeMsgpack decodes a single MessagePack value that must span all of
+// data. Maps become map[string]interface{} (non-string keys are formatted
+// with %v) so the result can be shown like JSON; bin values become []byte
+// and extension values a map with their type and hex encoded data, except
+// the timestamp extension, which becomes a time.Time.
+func DecodeMsgpack(data []byte) (interface{}, error) {
+	d := &msgpackDecoder{data: data}
+	value, err := d.value(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(data) {
+		return nil, fmt.Errorf("msgpack: %d trailing bytes", len(data)-d.pos)
+	}
+	return value, nil
+}
+
+// msgpackMaxDepth bounds nesting so hostile input cannot exhaust the stack.
+const msgpackMaxDepth = 256
+
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *msgpackDecoder) take(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, fmt.Errorf("msgpack: unexpected end of data")
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// uint reads a big endian unsigned integer of size bytes.
+func (d *msgpackDecoder) uint(size int) (uint64, error) {
+	b, err := d.take(size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// length reads a length prefix of size bytes.
+func (d *msgpackDecoder) length(size int) (int, error) {
+	n, err := d.uint(size)
+	if err != nil {
+		return 0, err
+	}
+	// Every element takes at least one byte, so longer lengths are corrupt
+	if n > uint64(len(d.data)-d.pos) {
+		return 0, fmt.Errorf("msgpack: length %d exceeds the data", n)
+	}
+	return int(n), nil
+}
+
+func (d *msgpackDecoder) value(depth int) (interface{}, error) {
+	if depth > msgpackMaxDepth {
+		return nil, fmt.Errorf("msgpack: nesting deeper than %d", msgpackMaxDepth)
+	}
+
+	b, err := d.take(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+
+	switch {
+	case c <= 0x7f: // positive fixint
+		return int64(c), nil
+	case c >= 0xe0: // negative fixint
+		return int64(int8(c)), nil
+	case c >= 0x80 && c <= 0x8f: // fixmap
+		return d.mapValue(int(c&0x0f), depth)
+	case c >= 0x90 && c <= 0x9f: // fixarray
+		return d.array(int(c&0x0f), depth)
+	case c >= 0xa0 && c <= 0xbf: // fixstr
+		s, err := d.take(int(c & 0x1f))
+		return string(s), err
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+
+	case 0xc4, 0xc5, 0xc6: // bin 8/16/32
+		n, err := d.length(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		bin, err := d.take(n)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), bin...), nil
+
+	case 0xc7, 0xc8, 0xc9: // ext 8/16/32
+		n, err := d.length(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.ext(n)
+
+	case 0xca: // float 32
+		n, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case 0xcb: // float 64
+		n, err := d.uint(8)
+		return math.Float64frombits(n), err
+
+	case 0xcc, 0xcd, 0xce, 0xcf: // uint 8/16/32/64
+		n, err := d.uint(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+		return int64(n), nil
+
+	case 0xd0: // int 8
+		n, err := d.uint(1)
+		return int64(int8(n)), err
+	case 0xd1: // int 16
+		n, err := d.uint(2)
+		return int64(int16(n)), err
+	case 0xd2: // int 32
+		n, err := d.uint(4)
+		return int64(int32(n)), err
+	case 0xd3: // int 64
+		n, err := d.uint(8)
+		return int64(n), err
+
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8: // fixext 1/2/4/8/16
+		return d.ext(1 << (c - 0xd4))
+
+	case 0xd9, 0xda, 0xdb: // str 8/16/32
+		n, err := d.length(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		s, err := d.take(n)
+		return string(s), err
+
+	case 0xdc, 0xdd: // array 16/32
+		n, err := d.length(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.array(n, depth)
+
+	case 0xde, 0xdf: // map 16/32
+		n, err := d.length(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.mapValue(n, depth)
+	}
+
+	return nil, fmt.Errorf("msgpack: invalid type byte 0x%02x", c)
+}
+
+func (d *msgpackDecoder) array(n int, depth int) (interface{}, error) {
+	items := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		item, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (d *msgpackDecoder) mapValue(n int, depth int) (interface{}, error) {
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		key, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(string)
+		if !ok {
+			name = fmt.Sprintf("%v", key)
+		}
+		m[name] = value
+	}
+	return m, nil
+}
+
+// ext reads the type and n data bytes of an extension value.
+func (d *msgpackDecoder) ext(n int) (interface{}, error) {
+	t, err := d.take(1)
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.take(n)
+	if err != nil {
+		return nil, err
+	}
+
+	// Type -1 is the timestamp extension
+	if int8(t[0]) == -1 {
+		switch n {
+		case 4:
+			return time.Unix(int64(binary.BigEndian.Uint32(data)), 0).UTC(), nil
+		case 8:
+			v := binary.BigEndian.Uint64(data)
+			return time.Unix(int64(v&0x3ffffffff), int64(v>>34)).UTC(), nil
+		case 12:
+			nsec := binary.BigEndian.Uint32(data[:4])
+			sec := int64(binary.BigEndian.Uint64(data[4:]))
+			return time.Unix(sec, int64(nsec)).UTC(), nil
+		}
+	}
+
+	return map[string]interface{}{
+		"ext_type": int64(int8(t[0])),
+		"data":     hex.EncodeToString(data),
+	}, nil
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDecodeMsgpack(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want interface{}
+	}{
+		{"nil", []byte{0xc0}, nil},
+		{"true", []byte{0xc3}, true},
+		{"positive fixint", []byte{0x7f}, int64(127)},
+		{"negative fixint", []byte{0xff}, int64(-1)},
+		{"uint16", []byte{0xcd, 0x01, 0x00}, int64(256)},
+		{"int32", []byte{0xd2, 0xff, 0xff, 0xff, 0xfe}, int64(-2)},
+		{"uint64 max", []byte{0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, uint64(1<<64 - 1)},
+		{"float64", []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}, 1.5},
+		{"fixstr", []byte{0xa3, 'a', 'b', 'c'}, "abc"},
+		{"str8", append([]byte{0xd9, 0x02}, "hi"...), "hi"},
+		{"bin8", []byte{0xc4, 0x02, 0x00, 0xff}, []byte{0x00, 0xff}},
+		{"fixarray", []byte{0x92, 0x01, 0xa1, 'x'}, []interface{}{int64(1), "x"}},
+		{"array16", []byte{0xdc, 0x00, 0x01, 0xc2}, []interface{}{false}},
+		{"fixmap", []byte{0x82, 0xa1, 'a', 0x01, 0x02, 0xc0},
+			map[string]interface{}{"a": int64(1), "2": nil}},
+		{"fixext", []byte{0xd4, 0x05, 0xab},
+			map[string]interface{}{"ext_type": int64(5), "data": "ab"}},
+		{"timestamp32", []byte{0xd6, 0xff, 0x00, 0x00, 0x00, 0x3c}, time.Unix(60, 0).UTC()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeMsgpack(tt.data)
+			if err != nil {
+				t.Fatalf("DecodeMsgpack(%x) returned error: %v", tt.data, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeMsgpack(%x) = %#v, want %#v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeMsgpackErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"truncated string", []byte{0xa3, 'a'}},
+		{"truncated array", []byte{0x92, 0x01}},
+		{"length beyond data", []byte{0xdd, 0xff, 0xff, 0xff, 0xff}},
+		{"trailing bytes", []byte{0x01, 0x02}},
+		{"never used byte", []byte{0xc1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := DecodeMsgpack(tt.data); err == nil {
+				t.Errorf("DecodeMsgpack(%x) = %#v, want an error", tt.data, got)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	var zipped bytes.Buffer
+	w := gzip.NewWriter(&zipped)
+	w.Write([]byte(`{"user": "ada"}`))
+	w.Close()
+
+	tests := []struct {
+		name   string
+		raw    []byte
+		steps  []string
+		format string
+	}{
+		{"plain text", []byte("hello world"), nil, ""},
+		{"base64 looking word", []byte("username"), nil, ""},
+		{"json", []byte(`[1, 2, 3]`), nil, "json"},
+		{"json number", []byte(`42`), nil, "json"},
+		{"json string", []byte(`"str"`), nil, "json"},
+		{"json true", []byte(`true`), nil, "json"},
+		{"json null", []byte(` null `), nil, "json"},
+		{"two json values", []byte(`1 2`), nil, ""},
+		{"gzip json", zipped.Bytes(), []string{"gzip"}, "json"},
+		{"base64 gzip json", []byte(base64.StdEncoding.EncodeToString(zipped.Bytes())), []string{"base64", "gzip"}, "json"},
+		{"base64 text", []byte(base64.StdEncoding.EncodeToString([]byte("hello world"))), []string{"base64"}, ""},
+		{"msgpack map", []byte{0x81, 0xa1, 'a', 0x01}, nil, "msgpack"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Decode(tt.raw)
+			if !reflect.DeepEqual(got.Steps, tt.steps) || got.Format != tt.format {
+				t.Errorf("Decode(%q) = steps %v format %q, want steps %v format %q",
+					tt.raw, got.Steps, got.Format, tt.steps, tt.format)
+			}
+		})
+	}
+}
+
+func TestDecodeTruncatedGzip(t *testing.T) {
+	var zipped bytes.Buffer
+	w := gzip.NewWriter(&zipped)
+	w.Write(bytes.Repeat([]byte("a"), MaxDecompressed+10))
+	w.Close()
+
+	got := Decode(zipped.Bytes())
+	if !got.Truncated || len(got.Data) != MaxDecompressed {
+		t.Errorf("Decode = truncated %v with %d bytes, want truncated with %d bytes", got.Truncated, len(got.Data), MaxDecompressed)
+	}
+}
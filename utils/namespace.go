@@ -0,0 +1,200 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultDelimiter separates namespace segments in keys such as
+// service:entity:id.
+const DefaultDelimiter = ":"
+
+// NamespaceScanLimit caps how many keys a single branch expansion reads, so
+// expanding the root of a huge keyspace stays bounded.
+const NamespaceScanLimit = 100000
+
+// NamespaceNode is one entry of a namespace level: either a folder grouping
+// every key that shares a prefix, or a key with no further delimiter.
+type NamespaceNode struct {
+	Name   string // segment shown in the tree
+	Prefix string // for folders, the full prefix including the delimiter
+	Key    string // for keys, the full key
+	Folder bool
+	Keys   int64 // keys under the folder, 1 for a key
+	Memory int64 // summed MEMORY USAGE in bytes
+	// Partial is set on folders of a truncated level, whose Keys and
+	// Memory are lower bounds
+	Partial bool
+}
+
+// NamespaceLevel is the content of one branch, folders first.
+type NamespaceLevel struct {
+	Nodes     []NamespaceNode
+	Scanned   int  // keys read to build the level
+	Truncated bool // NamespaceScanLimit was hit, counts are lower bounds
+}
+
+// ScanNamespace groups the keys under prefix by their next segment, using
+// SCAN MATCH prefix* so only the branch being expanded is read. Memory is
+// fetched with pipelined MEMORY USAGE calls.
+func (rc *RedisConnection) ScanNamespace(ctx context.Context, prefix, delimiter string) (*NamespaceLevel, error) {
+	client := rc.current()
+	if client == nil {
+		return nil, fmt.Errorf("not connected to Redis")
+	}
+	if delimiter == "" {
+		delimiter = DefaultDelimiter
+	}
+
+	level := &NamespaceLevel{}
+	folders := make(map[string]*NamespaceNode)
+	var keys []*NamespaceNode
+	seen := make(map[string]struct{})
+
+	// add files a key and its memory under the right node of the level
+	add := func(key string, memory int64) {
+		rest := strings.TrimPrefix(key, prefix)
+		if i := strings.Index(rest, delimiter); i >= 0 {
+			name := rest[:i]
+			folder, ok := folders[name]
+			if !ok {
+				folder = &NamespaceNode{Name: name, Prefix: prefix + name + delimiter, Folder: true}
+				folders[name] = folder
+			}
+			folder.Keys++
+			folder.Memory += memory
+			return
+		}
+		keys = append(keys, &NamespaceNode{Name: rest, Key: key, Keys: 1, Memory: memory})
+	}
+
+	batch := make([]string, 0, MetadataBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		memory, err := memoryUsage(ctx, client, batch)
+		if err != nil {
+			return err
+		}
+		for i, key := range batch {
+			add(key, memory[i])
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	it := rc.ScanKeys(ctx, ScanOptions{Match: GlobEscape(prefix) + "*"})
+	for it.Next() {
+		if _, dup := seen[it.Key()]; dup {
+			continue
+		}
+		if len(seen) >= NamespaceScanLimit {
+			level.Truncated = true
+			break
+		}
+		seen[it.Key()] = struct{}{}
+
+		batch = append(batch, it.Key())
+		if len(batch) == MetadataBatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	level.Scanned = len(seen)
+
+	for _, folder := range folders {
+		folder.Partial = level.Truncated
+		level.Nodes = append(level.Nodes, *folder)
+	}
+	sort.Slice(level.Nodes, func(i, j int) bool {
+		return level.Nodes[i].Name < level.Nodes[j].Name
+	})
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Name < keys[j].Name
+	})
+	for _, key := range keys {
+		level.Nodes = append(level.Nodes, *key)
+	}
+	return level, nil
+}
+
+// DeleteBranch removes every key starting with prefix, unlinking them in
+// pipelined batches as the scan goes. It returns how many keys were removed.
+func (rc *RedisConnection) DeleteBranch(ctx context.Context, prefix string) (int64, error) {
+	client := rc.current()
+	if client == nil {
+		return 0, fmt.Errorf("not connected to Redis")
+	}
+	if prefix == "" {
+		return 0, fmt.Errorf("refusing to delete an empty prefix, use flushall")
+	}
+
+	var deleted int64
+	batch := make([]string, 0, MetadataBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		cmds, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range batch {
+				pipe.Unlink(ctx, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, cmd := range cmds {
+			deleted += cmd.(*redis.IntCmd).Val()
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	it := rc.ScanKeys(ctx, ScanOptions{Match: GlobEscape(prefix) + "*"})
+	for it.Next() {
+		batch = append(batch, it.Key())
+		if len(batch) == MetadataBatchSize {
+			if err := flush(); err != nil {
+				return deleted, err
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return deleted, err
+	}
+	return deleted, flush()
+}
+
+// memoryUsage pipelines MEMORY USAGE for keys, reporting 0 for keys that
+// vanished in the meantime.
+func memoryUsage(ctx context.Context, client redis.UniversalClient, keys []string) ([]int64, error) {
+	cmds := make([]*redis.IntCmd, len(keys))
+	_, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.MemoryUsage(ctx, key)
+		}
+		return nil
+	})
+	if err != nil && isConnectionError(err) {
+		return nil, err
+	}
+
+	memory := make([]int64, len(keys))
+	for i, cmd := range cmds {
+		memory[i], _ = cmd.Result()
+	}
+	return memory, nil
+}
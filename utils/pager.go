@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultPageSize is the number of keys per page in the key browser.
+const DefaultPageSize = 200
+
+// KeyPager splits a SCAN of the keyspace into fixed size pages. It remembers
+// where every visited page started so it can go back, since SCAN itself only
+// moves forward. Pages reflect the keyspace at the time they are loaded.
+type KeyPager struct {
+	mu     sync.Mutex
+	rc     *RedisConnection
+	opts   ScanOptions
+	size   int
+	starts []ScanPosition // start of every visited page, the last is the current one
+	next   ScanPosition   // start of the page after the current one
+	more   bool           // whether there may be a page after the current one
+}
+
+// NewKeyPager returns a pager over the keys matching opts, positioned before
+// the first page. Call Load to fetch it.
+func (rc *RedisConnection) NewKeyPager(opts ScanOptions, size int) *KeyPager {
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+	return &KeyPager{
+		rc:     rc,
+		opts:   opts,
+		size:   size,
+		starts: []ScanPosition{{}},
+	}
+}
+
+// Page returns the 1-based number of the current page.
+func (p *KeyPager) Page() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.starts)
+}
+
+// HasNext reports whether there may be keys after the current page.
+func (p *KeyPager) HasNext() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.more
+}
+
+// HasPrev reports whether there is a page before the current one.
+func (p *KeyPager) HasPrev() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.starts) > 1
+}
+
+// Load (re)reads the current page.
+func (p *KeyPager) Load(ctx context.Context) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.load(ctx)
+}
+
+// Next moves to the following page and reads it. On the last page it
+// reloads the current one instead.
+func (p *KeyPager) Next(ctx context.Context) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.more {
+		return p.load(ctx)
+	}
+
+	p.starts = append(p.starts, p.next)
+	keys, err := p.load(ctx)
+	if err != nil {
+		p.starts = p.starts[:len(p.starts)-1]
+		return nil, err
+	}
+	if len(keys) == 0 {
+		// The scan had cursors left but no more keys, stay on the last page
+		p.starts = p.starts[:len(p.starts)-1]
+		keys, err = p.load(ctx)
+		p.more = false
+	}
+	return keys, err
+}
+
+// Prev moves to the preceding page and reads it. On the first page it
+// reloads the current one instead.
+func (p *KeyPager) Prev(ctx context.Context) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.starts) > 1 {
+		p.starts = p.starts[:len(p.starts)-1]
+	}
+	return p.load(ctx)
+}
+
+// load reads up to size keys from the current start. Duplicates SCAN may
+// return within a page are dropped.
+func (p *KeyPager) load(ctx context.Context) ([]string, error) {
+	start := p.starts[len(p.starts)-1]
+	it := p.rc.ScanKeysFrom(ctx, p.opts, start)
+
+	keys := make([]string, 0, p.size)
+	seen := make(map[string]struct{}, p.size)
+	for len(keys) < p.size && it.Next() {
+		if _, dup := seen[it.Key()]; dup {
+			continue
+		}
+		seen[it.Key()] = struct{}{}
+		keys = append(keys, it.Key())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	p.next, p.more = it.Position()
+	if p.more && p.next == start {
+		// Nothing was consumed, so the scan cannot make progress from here
+		p.more = false
+	}
+	return keys, nil
+}
@@ -0,0 +1,255 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/ssh"
+)
+
+// PubSubMessage is a message received by a Subscription.
+type PubSubMessage struct {
+	Time    time.Time
+	Channel string
+	Pattern string // the PSUBSCRIBE pattern it matched, if any
+	Payload string
+}
+
+// Subscription listens to channels and patterns on a client of its own,
+// since a connection in subscribed mode cannot run other commands. That
+// client is not replaced when the shared one is, by SELECT or a reconnect,
+// so subscriptions last until Close. go-redis subscribes again by itself
+// when the connection is re-dialled.
+type Subscription struct {
+	client   redis.UniversalClient
+	tunnel   *ssh.Client
+	pubsub   *redis.PubSub
+	messages chan PubSubMessage
+
+	mu       sync.Mutex
+	channels map[string]bool
+	patterns map[string]bool
+}
+
+// NewSubscription opens a subscription, not yet listening to anything, on
+// a new client to the server of the current connection.
+func (rc *RedisConnection) NewSubscription() (*Subscription, error) {
+	if !rc.IsConnected() {
+		return nil, fmt.Errorf("not connected to Redis")
+	}
+	d, err := rc.dial(rc.currentOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Subscription{
+		client:   d.client,
+		tunnel:   d.tunnel,
+		pubsub:   d.client.Subscribe(rc.ctx),
+		messages: make(chan PubSubMessage, 100),
+		channels: make(map[string]bool),
+		patterns: make(map[string]bool),
+	}
+	go s.receive()
+	return s, nil
+}
+
+// receive stamps messages as they arrive and hands them on until the
+// subscription is closed
+func (s *Subscription) receive() {
+	defer close(s.messages)
+	for msg := range s.pubsub.Channel() {
+		s.messages <- PubSubMessage{
+			Time:    time.Now(),
+			Channel: msg.Channel,
+			Pattern: msg.Pattern,
+			Payload: msg.Payload,
+		}
+	}
+}
+
+// Messages delivers the messages received, and is closed by Close.
+func (s *Subscription) Messages() <-chan PubSubMessage {
+	return s.messages
+}
+
+// Subscribe starts listening to channels.
+func (s *Subscription) Subscribe(ctx context.Context, channels ...string) error {
+	if err := s.pubsub.Subscribe(ctx, channels...); err != nil {
+		return authError(err)
+	}
+	s.track(s.channels, channels, true)
+	return nil
+}
+
+// PSubscribe starts listening to channels matching the glob patterns.
+func (s *Subscription) PSubscribe(ctx context.Context, patterns ...string) error {
+	if err := s.pubsub.PSubscribe(ctx, patterns...); err != nil {
+		return authError(err)
+	}
+	s.track(s.patterns, patterns, true)
+	return nil
+}
+
+// Unsubscribe stops listening to channels, or to every channel when none
+// are given.
+func (s *Subscription) Unsubscribe(ctx context.Context, channels ...string) error {
+	if len(channels) == 0 {
+		channels = s.Channels()
+	}
+	if len(channels) == 0 {
+		return nil
+	}
+	if err := s.pubsub.Unsubscribe(ctx, channels...); err != nil {
+		return err
+	}
+	s.track(s.channels, channels, false)
+	return nil
+}
+
+// PUnsubscribe stops listening to patterns, or to every pattern when none
+// are given.
+func (s *Subscription) PUnsubscribe(ctx context.Context, patterns ...string) error {
+	if len(patterns) == 0 {
+		patterns = s.Patterns()
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+	if err := s.pubsub.PUnsubscribe(ctx, patterns...); err != nil {
+		return err
+	}
+	s.track(s.patterns, patterns, false)
+	return nil
+}
+
+func (s *Subscription) track(set map[string]bool, names []string, subscribed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range names {
+		if subscribed {
+			set[name] = true
+		} else {
+			delete(set, name)
+		}
+	}
+}
+
+// Channels returns the channels subscribed to, sorted.
+func (s *Subscription) Channels() []string {
+	return s.sorted(s.channels)
+}
+
+// Patterns returns the patterns subscribed to, sorted.
+func (s *Subscription) Patterns() []string {
+	return s.sorted(s.patterns)
+}
+
+func (s *Subscription) sorted(set map[string]bool) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Close unsubscribes from everything and closes the connection.
+func (s *Subscription) Close() error {
+	err := s.pubsub.Close()
+	if releaseErr := release(s.client, s.tunnel, nil); err == nil {
+		err = releaseErr
+	}
+	return err
+}
+
+// Publish sends message to channel, returning how many clients received it.
+func (rc *RedisConnection) Publish(channel, message string) (int64, error) {
+	client := rc.current()
+	if client == nil {
+		return 0, fmt.Errorf("not connected to Redis")
+	}
+
+	return client.Publish(rc.ctx, channel, message).Result()
+}
+
+// ChannelInfo is an active channel and how many clients subscribe to it.
+type ChannelInfo struct {
+	Name        string
+	Subscribers int64
+}
+
+// ActiveChannels lists the channels matching pattern (all when empty) that
+// have subscribers, with PUBSUB CHANNELS and PUBSUB NUMSUB, sorted by name.
+// It also returns the number of patterns subscribed to (PUBSUB NUMPAT).
+func (rc *RedisConnection) ActiveChannels(pattern string) ([]ChannelInfo, int64, error) {
+	client := rc.current()
+	if client == nil {
+		return nil, 0, fmt.Errorf("not connected to Redis")
+	}
+
+	ctx := rc.ctx
+	names, err := client.PubSubChannels(ctx, pattern).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	patterns, err := client.PubSubNumPat(ctx).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(names) == 0 {
+		return nil, patterns, nil
+	}
+
+	counts, err := client.PubSubNumSub(ctx, names...).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	sort.Strings(names)
+	channels := make([]ChannelInfo, len(names))
+	for i, name := range names {
+		channels[i] = ChannelInfo{Name: name, Subscribers: counts[name]}
+	}
+	return channels, patterns, nil
+}
+
+// IsSubscribeCommand reports whether command puts a connection in
+// subscribed mode (or streams replies, like MONITOR), so it cannot run on
+// the shared connection pool.
+func IsSubscribeCommand(command string) bool {
+	switch strings.ToLower(command) {
+	case "subscribe", "psubscribe", "ssubscribe", "monitor":
+		return true
+	}
+	return false
+}
+
+// WritePubSubLog writes messages one per line: the time, the channel, the
+// pattern (or -) and the payload, separated by tabs. Payloads that contain
+// tabs, line breaks or binary data are written Go-quoted.
+func WritePubSubLog(w io.Writer, messages []PubSubMessage) error {
+	for _, m := range messages {
+		pattern := m.Pattern
+		if pattern == "" {
+			pattern = "-"
+		}
+		payload := m.Payload
+		if strings.ContainsAny(payload, "\t\r\n\"") || !IsReadable([]byte(payload)) {
+			payload = strconv.Quote(payload)
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			m.Time.Format("2006-01-02T15:04:05.000Z07:00"), m.Channel, pattern, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
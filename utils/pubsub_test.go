@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWritePubSubLog(t *testing.T) {
+	at := time.Date(2024, 3, 1, 12, 30, 45, 123000000, time.UTC)
+	messages := []PubSubMessage{
+		{Time: at, Channel: "news", Payload: "hello world"},
+		{Time: at, Channel: "news.sport", Pattern: "news.*", Payload: "line one\nline two"},
+		{Time: at, Channel: "bin", Payload: "\x00\x01"},
+	}
+
+	var buf bytes.Buffer
+	if err := WritePubSubLog(&buf, messages); err != nil {
+		t.Fatalf("WritePubSubLog returned error: %v", err)
+	}
+	want := "2024-03-01T12:30:45.123Z\tnews\t-\thello world\n" +
+		"2024-03-01T12:30:45.123Z\tnews.sport\tnews.*\t\"line one\\nline two\"\n" +
+		"2024-03-01T12:30:45.123Z\tbin\t-\t\"\\x00\\x01\"\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WritePubSubLog wrote\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestIsSubscribeCommand(t *testing.T) {
+	for command, want := range map[string]bool{
+		"subscribe":   true,
+		"PSUBSCRIBE":  true,
+		"ssubscribe":  true,
+		"monitor":     true,
+		"publish":     false,
+		"unsubscribe": false,
+		"get":         false,
+	} {
+		if got := IsSubscribeCommand(command); got != want {
+			t.Errorf("IsSubscribeCommand(%q) = %v, want %v", command, got, want)
+		}
+	}
+}
@@ -2,97 +2,591 @@ package utils
 
 import (
     "context"
+    "errors"
     "fmt"
+    "net"
+    "strconv"
     "strings"
+    "sync"
     "time"
     "sort"
     
     "github.com/redis/go-redis/v9"
+    "golang.org/x/crypto/ssh"
 )
 
 type RedisConnection struct {
-    client *redis.Client
-    ctx    context.Context
+    ctx context.Context
+
+    // live is the current client, shared with the copies made by WithContext
+    live *liveClient
+    // onEvent receives connection events (failovers, resolved masters) for the Logs pane
+    onEvent func(message string)
+    // health tracks the state reported by the health monitor
+    health *healthState
+}
+
+// liveClient is the client in use and what it was built from. Connecting,
+// switching databases and closing replace it under mu from the UI
+// goroutine, while page loads and commands read it from others.
+type liveClient struct {
+    mu     sync.RWMutex
+    client redis.UniversalClient
+    // options are the settings of the current client, reused to reconnect
+    options ConnectOptions
+    // tunnel is the SSH connection carrying the current client, if any
+    tunnel *ssh.Client
+    // stopWatchers cancels background watchers tied to the current client
+    stopWatchers context.CancelFunc
 }
 
 func NewRedisConnection() *RedisConnection {
     return &RedisConnection{
-        ctx: context.Background(),
+        ctx:    context.Background(),
+        live:   &liveClient{},
+        health: &healthState{},
+    }
+}
+
+// current returns the client to run an operation on, nil when not
+// connected. Operations take it once up front, so a reconnect on another
+// goroutine cannot swap clients halfway through; an operation still
+// running on a replaced client fails with redis.ErrClosed.
+func (rc *RedisConnection) current() redis.UniversalClient {
+    rc.live.mu.RLock()
+    defer rc.live.mu.RUnlock()
+    return rc.live.client
+}
+
+// currentOptions returns the settings the current client was built from.
+func (rc *RedisConnection) currentOptions() ConnectOptions {
+    rc.live.mu.RLock()
+    defer rc.live.mu.RUnlock()
+    return rc.live.options
+}
+
+// SetEventHandler registers a callback for connection events such as
+// Sentinel failovers. The handler may be called from background goroutines.
+func (rc *RedisConnection) SetEventHandler(handler func(message string)) {
+    rc.onEvent = handler
+}
+
+func (rc *RedisConnection) emit(format string, args ...interface{}) {
+    if rc.onEvent != nil {
+        rc.onEvent(fmt.Sprintf(format, args...))
     }
 }
 
+const (
+    ConnectionStandalone = ""
+    ConnectionSentinel   = "sentinel"
+    ConnectionCluster    = "cluster"
+    ConnectionUnix       = "unix"
+)
+
+// ConnectOptions describes how to reach and authenticate against a Redis server.
+type ConnectOptions struct {
+    Type     string
+    // Host is the socket path when Type is ConnectionUnix
+    Host     string
+    Port     string
+    Username string
+    Password string
+    DB       int
+    // DBSet makes DB override a database given in a redis:// URL, for
+    // switching databases with use db or SELECT
+    DBSet    bool
+    TLS      TLSOptions
+
+    // Sentinel settings, used when Type is ConnectionSentinel
+    MasterName       string
+    SentinelAddrs    []string
+    SentinelUsername string
+    SentinelPassword string
+
+    // Extra seed nodes, used when Type is ConnectionCluster
+    ClusterAddrs []string
+
+    // Optional bastion host the connection is tunnelled through
+    SSH SSHOptions
+
+    // Network timeouts, go-redis defaults are used when zero
+    DialTimeout  time.Duration
+    ReadTimeout  time.Duration
+    WriteTimeout time.Duration
+}
+
+// WithContext returns a shallow copy of the connection whose operations run
+// under ctx, so cancelling ctx aborts them. The copy always uses the live
+// client, even after a reconnect, and must not be used to connect or close.
+func (rc *RedisConnection) WithContext(ctx context.Context) *RedisConnection {
+    clone := *rc
+    clone.ctx = ctx
+    return &clone
+}
+
+// Context returns the context operations on this connection run under.
+func (rc *RedisConnection) Context() context.Context {
+    return rc.ctx
+}
+
+// errClientReplaced stops multi-step operations whose client was replaced
+// by a reconnect or database switch before they finished.
+var errClientReplaced = errors.New("the connection was replaced while the operation ran")
+
+var (
+    ErrAuthRequired  = errors.New("authentication required: the server expects a password (NOAUTH)")
+    ErrWrongPassword = errors.New("authentication failed: invalid username/password or the user is disabled (WRONGPASS)")
+)
+
+// authError maps NOAUTH/WRONGPASS replies onto the exported sentinel errors so
+// callers can tell authentication problems apart from network failures.
+func authError(err error) error {
+    if err == nil {
+        return nil
+    }
+
+    msg := err.Error()
+    switch {
+    case strings.HasPrefix(msg, "NOAUTH"):
+        return fmt.Errorf("%w: %s", ErrAuthRequired, msg)
+    case strings.HasPrefix(msg, "WRONGPASS"),
+        strings.Contains(msg, "invalid password"),
+        strings.Contains(msg, "without any password configured"):
+        return fmt.Errorf("%w: %s", ErrWrongPassword, msg)
+    }
+    return err
+}
+
 func (rc *RedisConnection) Connect(host string, port string) error {
+    return rc.ConnectWithOptions(ConnectOptions{Host: host, Port: port})
+}
+
+func (rc *RedisConnection) ConnectWithOptions(options ConnectOptions) error {
+    d, err := rc.dial(options)
+    if err != nil {
+        return err
+    }
+    rc.install(d, nil)
+    return nil
+}
+
+// dialed is a working client that is not live yet.
+type dialed struct {
+    client  redis.UniversalClient
+    tunnel  *ssh.Client
+    options ConnectOptions
+    // kept for the Sentinel watcher, which reaches the sentinels the same way
+    failoverOpts *redis.FailoverOptions
+}
+
+// dial opens the SSH tunnel, if any, and a client for options, and pings it.
+func (rc *RedisConnection) dial(options ConnectOptions) (*dialed, error) {
+    var client redis.UniversalClient
+    var failoverOpts *redis.FailoverOptions
+
+    // The tunnel has to be up before go-redis dials through it
+    var tunnel *ssh.Client
+    if options.SSH.active() {
+        var err error
+        if tunnel, err = options.SSH.openTunnel(); err != nil {
+            return nil, err
+        }
+    }
+    fail := func(err error) (*dialed, error) {
+        if tunnel != nil {
+            tunnel.Close()
+        }
+        return nil, err
+    }
+
+    switch options.Type {
+    case ConnectionCluster:
+        clusterOpts, err := clusterOptions(options)
+        if err != nil {
+            return fail(err)
+        }
+        if tunnel != nil {
+            clusterOpts.Dialer = tunnelDialer(tunnel, clusterOpts.TLSConfig)
+        }
+        client = redis.NewClusterClient(clusterOpts)
+    case ConnectionSentinel:
+        var err error
+        failoverOpts, err = sentinelOptions(options)
+        if err != nil {
+            return fail(err)
+        }
+        if tunnel != nil {
+            failoverOpts.Dialer = tunnelDialer(tunnel, failoverOpts.TLSConfig)
+        }
+        client = redis.NewFailoverClient(failoverOpts)
+    case ConnectionStandalone, ConnectionUnix:
+        opts, err := standaloneOptions(options)
+        if err != nil {
+            return fail(err)
+        }
+        if tunnel != nil {
+            opts.Dialer = tunnelDialer(tunnel, opts.TLSConfig)
+        }
+        client = redis.NewClient(opts)
+    default:
+        return fail(fmt.Errorf("unknown connection type: %s", options.Type))
+    }
+
+    // Test the connection
+    _, err := client.Ping(rc.ctx).Result()
+    if err != nil {
+        client.Close()
+        return fail(fmt.Errorf("failed to connect to Redis: %w", authError(err)))
+    }
+
+    if standalone, ok := client.(*redis.Client); ok {
+        // URLs may pick the database themselves
+        options.DB = standalone.Options().DB
+    }
+
+    return &dialed{client: client, tunnel: tunnel, options: options, failoverOpts: failoverOpts}, nil
+}
+
+// install makes d the live client, releasing the previous one, and starts
+// its watchers. A reconnect passes the client it replaces: if another
+// connection was made in the meantime, d is released instead and install
+// reports false.
+func (rc *RedisConnection) install(d *dialed, replacing redis.UniversalClient) bool {
+    // The watchers outlive the operation that connected, which may run on
+    // a cancellable copy
+    watchCtx, cancel := context.WithCancel(context.WithoutCancel(rc.ctx))
+    live := rc.live
+    live.mu.Lock()
+    if replacing != nil && live.client != replacing {
+        live.mu.Unlock()
+        release(d.client, d.tunnel, cancel)
+        return false
+    }
+    oldClient, oldTunnel, oldStop := live.client, live.tunnel, live.stopWatchers
+    live.client, live.tunnel, live.options, live.stopWatchers = d.client, d.tunnel, d.options, cancel
+    live.mu.Unlock()
+    if oldClient != nil {
+        if replacing == nil {
+            rc.setState(StateDisconnected)
+        }
+        release(oldClient, oldTunnel, oldStop)
+    }
+    rc.setState(StateConnected)
+
+    go rc.monitorHealth(watchCtx, d.client, d.options)
+    if d.failoverOpts != nil {
+        go rc.watchSentinel(watchCtx, d.failoverOpts)
+    }
+    return true
+}
+
+func standaloneOptions(options ConnectOptions) (*redis.Options, error) {
+    var opts *redis.Options
+
     // Check if the input looks like a full Redis URL
-    if strings.HasPrefix(host, "redis://") || strings.HasPrefix(host, "rediss://") {
-        opts, err := redis.ParseURL(host)
+    if strings.HasPrefix(options.Host, "redis://") || strings.HasPrefix(options.Host, "rediss://") {
+        parsed, err := redis.ParseURL(options.Host)
         if err != nil {
-            return fmt.Errorf("failed to parse Redis URL: %v", err)
+            return nil, fmt.Errorf("failed to parse Redis URL: %v", err)
         }
+        opts = parsed
 
-        rc.client = redis.NewClient(opts)
+        // Explicit credentials only fill in what the URL left out
+        if opts.Username == "" {
+            opts.Username = options.Username
+        }
+        if opts.Password == "" {
+            opts.Password = options.Password
+        }
+        if options.DBSet || opts.DB == 0 {
+            opts.DB = options.DB
+        }
+        if options.DialTimeout > 0 {
+            opts.DialTimeout = options.DialTimeout
+        }
+        if options.ReadTimeout > 0 {
+            opts.ReadTimeout = options.ReadTimeout
+        }
+        if options.WriteTimeout > 0 {
+            opts.WriteTimeout = options.WriteTimeout
+        }
+    } else if options.Type == ConnectionUnix {
+        opts = &redis.Options{
+            Network:      "unix",
+            Addr:         options.Host,
+            Username:     options.Username,
+            Password:     options.Password,
+            DB:           options.DB,
+            DialTimeout:  options.DialTimeout,
+            ReadTimeout:  options.ReadTimeout,
+            WriteTimeout: options.WriteTimeout,
+        }
     } else {
-        // Existing localhost/custom host connection logic
-        rc.client = redis.NewClient(&redis.Options{
-            Addr:     fmt.Sprintf("%s:%s", host, port),
-            Password: "", // no password set
-            DB:       0,  // use default DB
-        })
+        opts = &redis.Options{
+            Addr:         fmt.Sprintf("%s:%s", options.Host, options.Port),
+            Username:     options.Username,
+            Password:     options.Password,
+            DB:           options.DB,
+            DialTimeout:  options.DialTimeout,
+            ReadTimeout:  options.ReadTimeout,
+            WriteTimeout: options.WriteTimeout,
+        }
     }
 
-    // Test the connection
-    _, err := rc.client.Ping(rc.ctx).Result()
-    if err != nil {
-        return fmt.Errorf("failed to connect to Redis: %v", err)
+    // Let context deadlines bound reads and writes, not just the fixed timeouts
+    opts.ContextTimeoutEnabled = true
+
+    // rediss:// URLs already carry a TLS config, the options only refine it
+    if opts.TLSConfig != nil || options.TLS.active() {
+        tlsConfig, err := options.TLS.apply(opts.TLSConfig)
+        if err != nil {
+            return nil, fmt.Errorf("invalid TLS settings: %v", err)
+        }
+        if tlsConfig.ServerName == "" {
+            tlsConfig.ServerName, _, _ = net.SplitHostPort(opts.Addr)
+        }
+        opts.TLSConfig = tlsConfig
     }
 
-    return nil
+    return opts, nil
 }
 
 func (rc *RedisConnection) Close() error {
-    if rc.client != nil {
-        return rc.client.Close()
+    live := rc.live
+    live.mu.Lock()
+    client, tunnel, stop := live.client, live.tunnel, live.stopWatchers
+    live.client, live.tunnel, live.stopWatchers = nil, nil, nil
+    live.mu.Unlock()
+
+    rc.setState(StateDisconnected)
+    return release(client, tunnel, stop)
+}
+
+// release stops the watchers of a client that is no longer live and closes
+// it, along with the tunnel carrying it.
+func release(client redis.UniversalClient, tunnel *ssh.Client, stopWatchers context.CancelFunc) error {
+    if stopWatchers != nil {
+        stopWatchers()
     }
-    return nil
+    var err error
+    if client != nil {
+        err = client.Close()
+    }
+    // The tunnel is torn down together with the client it carries
+    if tunnel != nil {
+        tunnel.Close()
+    }
+    return err
 }
 
 func (rc *RedisConnection) IsConnected() bool {
-    return rc.client != nil
+    return rc.current() != nil
 }
 
+// GetAllKeys returns every key using incremental SCAN, never KEYS.
 func (rc *RedisConnection) GetAllKeys() ([]string, error) {
-    if rc.client == nil {
+    client := rc.current()
+    if client == nil {
         return nil, fmt.Errorf("not connected to Redis")
     }
-    
-    return rc.client.Keys(rc.ctx, "*").Result()
+
+    return rc.ScanAll(rc.ctx, ScanOptions{})
+}
+
+// Ping checks that the server is still reachable.
+func (rc *RedisConnection) Ping() error {
+    client := rc.current()
+    if client == nil {
+        return fmt.Errorf("not connected to Redis")
+    }
+
+    return authError(client.Ping(rc.ctx).Err())
 }
 
+// GetValue returns the value of key as text. Strings are returned as-is,
+// other types are read with their own commands and encoded as JSON.
 func (rc *RedisConnection) GetValue(key string) (string, error) {
-    if rc.client == nil {
+    client := rc.current()
+    if client == nil {
         return "", fmt.Errorf("not connected to Redis")
     }
-    
-    return rc.client.Get(rc.ctx, key).Result()
+
+    value, err := rc.GetTypedValue(key)
+    if err != nil {
+        return "", err
+    }
+    return value.Text(), nil
+}
+
+// Rename renames a key, keeping its type and TTL. On a cluster, names in
+// different hash slots are moved with DUMP, RESTORE and DEL instead.
+func (rc *RedisConnection) Rename(key string, newKey string) error {
+    client := rc.current()
+    if client == nil {
+        return fmt.Errorf("not connected to Redis")
+    }
+
+    err := client.Rename(rc.ctx, key, newKey).Err()
+    if isCrossSlot(err) {
+        _, err = rc.moveKey(client, key, newKey, true)
+    }
+    return err
+}
+
+// RenameNX renames key only if newKey does not exist yet, reporting
+// whether it did.
+func (rc *RedisConnection) RenameNX(key string, newKey string) (bool, error) {
+    client := rc.current()
+    if client == nil {
+        return false, fmt.Errorf("not connected to Redis")
+    }
+
+    renamed, err := client.RenameNX(rc.ctx, key, newKey).Result()
+    if isCrossSlot(err) {
+        return rc.moveKey(client, key, newKey, false)
+    }
+    return renamed, err
+}
+
+// isCrossSlot reports whether a cluster refused a command because its keys
+// live in different hash slots.
+func isCrossSlot(err error) bool {
+    return err != nil && strings.HasPrefix(err.Error(), "CROSSSLOT")
+}
+
+// moveKey renames key across hash slots: the value is restored under newKey
+// with its TTL, then key is deleted. Unlike RENAME this is not atomic. It
+// reports false when newKey exists and replace is not set.
+func (rc *RedisConnection) moveKey(client redis.UniversalClient, key string, newKey string, replace bool) (bool, error) {
+    restored, err := rc.restoreCopy(client, key, newKey, replace)
+    if !restored || err != nil {
+        return false, err
+    }
+    return true, client.Del(rc.ctx, key).Err()
+}
+
+// restoreCopy copies key to newKey with DUMP and RESTORE, keeping its TTL,
+// which works across hash slots and on servers without COPY. It reports
+// false when newKey exists and replace is not set.
+func (rc *RedisConnection) restoreCopy(client redis.UniversalClient, key string, newKey string, replace bool) (bool, error) {
+    ctx := rc.ctx
+    dump, err := client.Dump(ctx, key).Result()
+    if err == redis.Nil {
+        return false, fmt.Errorf("key '%s' does not exist", key)
+    } else if err != nil {
+        return false, err
+    }
+    ttl, err := client.PTTL(ctx, key).Result()
+    if err != nil {
+        return false, err
+    }
+    if ttl < 0 {
+        ttl = 0
+    }
+
+    if replace {
+        err = client.RestoreReplace(ctx, newKey, ttl, dump).Err()
+    } else {
+        err = client.Restore(ctx, newKey, ttl, dump).Err()
+        if err != nil && strings.Contains(err.Error(), "BUSYKEY") {
+            return false, nil
+        }
+    }
+    return err == nil, err
+}
+
+// Duplicate copies key with its TTL to newKey, failing if key does not
+// exist or newKey does. It uses COPY and falls back to DUMP and RESTORE on
+// servers before 6.2 and across the hash slots of a cluster.
+func (rc *RedisConnection) Duplicate(key string, newKey string) error {
+    client := rc.current()
+    if client == nil {
+        return fmt.Errorf("not connected to Redis")
+    }
+
+    copied, err := client.Copy(rc.ctx, key, newKey, rc.DB(), false).Result()
+    if err == nil {
+        if copied == 0 {
+            // COPY also returns 0 when there is nothing to copy
+            exists, err := client.Exists(rc.ctx, key).Result()
+            if err != nil {
+                return err
+            }
+            if exists == 0 {
+                return fmt.Errorf("key '%s' does not exist", key)
+            }
+            return fmt.Errorf("key '%s' already exists", newKey)
+        }
+        return nil
+    }
+    if !isUnknownCommand(err) && !isCrossSlot(err) {
+        return err
+    }
+
+    restored, err := rc.restoreCopy(client, key, newKey, false)
+    if err == nil && !restored {
+        return fmt.Errorf("key '%s' already exists", newKey)
+    }
+    return err
+}
+
+// Expire sets the TTL of a key without touching its value. A zero TTL
+// removes the expiration.
+func (rc *RedisConnection) Expire(key string, ttl time.Duration) error {
+    client := rc.current()
+    if client == nil {
+        return fmt.Errorf("not connected to Redis")
+    }
+
+    if ttl <= 0 {
+        return client.Persist(rc.ctx, key).Err()
+    }
+    return client.PExpire(rc.ctx, key, ttl).Err()
 }
 
 func (rc *RedisConnection) GetTTL(key string) (time.Duration, error) {
-    if rc.client == nil {
+    client := rc.current()
+    if client == nil {
         return 0, fmt.Errorf("not connected to Redis")
     }
     
-    return rc.client.TTL(rc.ctx, key).Result()
+    // PTTL keeps millisecond precision for keys about to expire
+    return client.PTTL(rc.ctx, key).Result()
 }
 
+// ExecuteCommand runs a command line typed by the user, splitting it into
+// arguments with redis-cli quoting rules.
 func (rc *RedisConnection) ExecuteCommand(cmd string) (interface{}, error) {
-    if rc.client == nil {
+    client := rc.current()
+    if client == nil {
         return nil, fmt.Errorf("not connected to Redis")
     }
     
-    // Split the command into parts
-    parts := strings.Fields(cmd)
+    // Split the command into parts, honouring quotes and escapes
+    parts, err := SplitArgs(cmd)
+    if err != nil {
+        return nil, err
+    }
+
+    return rc.ExecuteArgs(parts)
+}
+
+// ExecuteArgs runs a command whose arguments are already separated.
+func (rc *RedisConnection) ExecuteArgs(parts []string) (interface{}, error) {
+    client := rc.current()
+    if client == nil {
+        return nil, fmt.Errorf("not connected to Redis")
+    }
+
     if len(parts) == 0 {
         return nil, fmt.Errorf("empty command")
     }
+
+    // These would leave a pooled connection in subscribed mode
+    if IsSubscribeCommand(parts[0]) {
+        return nil, fmt.Errorf("%s cannot run on the shared connection, use the pub/sub console", strings.ToUpper(parts[0]))
+    }
     
     // Create a slice of interfaces starting with the command
     args := make([]interface{}, len(parts))
@@ -100,26 +594,46 @@ func (rc *RedisConnection) ExecuteCommand(cmd string) (interface{}, error) {
         args[i] = part
     }
     
-    // Execute the command
-    return rc.client.Do(rc.ctx, args...).Result()
+    // SELECT would only switch one pooled connection, switch the whole pool instead
+    if strings.EqualFold(parts[0], "select") && len(parts) == 2 {
+        index, err := strconv.Atoi(parts[1])
+        if err != nil {
+            return nil, fmt.Errorf("invalid database index: %s", parts[1])
+        }
+        if err := rc.SelectDB(index); err != nil {
+            return nil, err
+        }
+        return "OK", nil
+    }
+
+    // Keyspace-wide commands have to reach every cluster master
+    if result, handled, err := rc.executeOnAllMasters(client, args); handled {
+        return result, authError(err)
+    }
+
+    // Execute the command, cluster clients route it by key slot
+    result, err := client.Do(rc.ctx, args...).Result()
+    return result, authError(err)
 }
 
 func (rc *RedisConnection) SetKeyWithTTL(key string, value string, ttl time.Duration) error {
-    if rc.client == nil {
+    client := rc.current()
+    if client == nil {
         return fmt.Errorf("not connected to Redis")
     }
 
     // If TTL is 0, set the key without expiration
     if ttl == 0 {
-        return rc.client.Set(rc.ctx, key, value, 0).Err()
+        return client.Set(rc.ctx, key, value, 0).Err()
     }
 
     // Set key with specified TTL
-    return rc.client.Set(rc.ctx, key, value, ttl).Err()
+    return client.Set(rc.ctx, key, value, ttl).Err()
 }
 
 func (rc *RedisConnection) UpdateKey(key string, value string, keepTTL bool) error {
-    if rc.client == nil {
+    client := rc.current()
+    if client == nil {
         return fmt.Errorf("not connected to Redis")
     }
 
@@ -127,7 +641,7 @@ func (rc *RedisConnection) UpdateKey(key string, value string, keepTTL bool) err
     var currentTTL time.Duration
     var err error
     if keepTTL {
-        currentTTL, err = rc.client.TTL(rc.ctx, key).Result()
+        currentTTL, err = client.TTL(rc.ctx, key).Result()
         if err != nil {
             return fmt.Errorf("error checking TTL: %v", err)
         }
@@ -136,20 +650,21 @@ func (rc *RedisConnection) UpdateKey(key string, value string, keepTTL bool) err
     // Set the new value
     if keepTTL && currentTTL > 0 {
         // Set with the existing TTL
-        return rc.client.Set(rc.ctx, key, value, currentTTL).Err()
+        return client.Set(rc.ctx, key, value, currentTTL).Err()
     } else {
         // Set without TTL
-        return rc.client.Set(rc.ctx, key, value, 0).Err()
+        return client.Set(rc.ctx, key, value, 0).Err()
     }
 }
 
 func (rc *RedisConnection) KeyExists(key string) (bool, error) {
-    if rc.client == nil {
+    client := rc.current()
+    if client == nil {
         return false, fmt.Errorf("not connected to Redis")
     }
 
     // Check if the key exists
-    exists, err := rc.client.Exists(rc.ctx, key).Result()
+    exists, err := client.Exists(rc.ctx, key).Result()
     if err != nil {
         return false, err
     }
@@ -159,7 +674,8 @@ func (rc *RedisConnection) KeyExists(key string) (bool, error) {
 
 // Optional: Refresh data method if needed
 func (rc *RedisConnection) RefreshData() ([]string, error) {
-    if rc.client == nil {
+    client := rc.current()
+    if client == nil {
         return nil, fmt.Errorf("not connected to Redis")
     }
 
@@ -174,12 +690,15 @@ func (rc *RedisConnection) RefreshData() ([]string, error) {
 }
 
 func (rc *RedisConnection) FlushAll() error {
-    if rc.client == nil {
+    client := rc.current()
+    if client == nil {
         return fmt.Errorf("not connected to Redis")
     }
     
-    // Execute FLUSHALL command
-    err := rc.client.FlushAll(rc.ctx).Err()
+    // Execute FLUSHALL command on every master
+    err := forEachMaster(rc.ctx, client, func(ctx context.Context, client *redis.Client) error {
+        return client.FlushAll(ctx).Err()
+    })
     if err != nil {
         return fmt.Errorf("error flushing Redis: %v", err)
     }
@@ -191,32 +710,25 @@ func (rc *RedisConnection) FlushAll() error {
 
 type KeyMemoryInfo struct {
     Key   string
+    Type  string
     Bytes int64
 }
 
 func (rc *RedisConnection) GetStats() (map[string]interface{}, error) {
-    if rc.client == nil {
+    client := rc.current()
+    if client == nil {
         return nil, fmt.Errorf("not connected to Redis")
     }
 
     stats := make(map[string]interface{})
     
-    // Get INFO stats
-    info, err := rc.client.Info(rc.ctx, "stats").Result()
+    // Get INFO stats, summed over every master
+    info, err := infoSum(rc.ctx, client, "stats", "keyspace_hits", "keyspace_misses")
     if err != nil {
         return nil, fmt.Errorf("error getting Redis stats: %v", err)
     }
 
-    // Parse keyspace hits and misses
-    var keyspaceHits, keyspaceMisses int64
-    for _, line := range strings.Split(info, "\n") {
-        if strings.HasPrefix(line, "keyspace_hits:") {
-            fmt.Sscanf(line, "keyspace_hits:%d", &keyspaceHits)
-        }
-        if strings.HasPrefix(line, "keyspace_misses:") {
-            fmt.Sscanf(line, "keyspace_misses:%d", &keyspaceMisses)
-        }
-    }
+    keyspaceHits, keyspaceMisses := info["keyspace_hits"], info["keyspace_misses"]
 
     // Calculate hit ratio
     hitRatio := float64(0)
@@ -228,39 +740,45 @@ func (rc *RedisConnection) GetStats() (map[string]interface{}, error) {
     stats["total_misses"] = keyspaceMisses
 
     // Get total keys
-    dbSize, err := rc.client.DBSize(rc.ctx).Result()
+    dbSize, err := dbSize(rc.ctx, client)
     if err != nil {
         return nil, fmt.Errorf("error getting DB size: %v", err)
     }
     stats["total_keys"] = dbSize
 
-    // Get keys with TTL
+    // Walk the keyspace once, counting keys with TTL and collecting memory usage
     keysWithTTL := int64(0)
-    keys, err := rc.client.Keys(rc.ctx, "*").Result()
-    if err != nil {
-        return nil, fmt.Errorf("error getting keys: %v", err)
-    }
+    keysByType := make(map[string]int64)
+    var topMemoryKeys []KeyMemoryInfo
 
-    for _, key := range keys {
-        ttl, err := rc.client.TTL(rc.ctx, key).Result()
+    it := rc.ScanKeys(rc.ctx, ScanOptions{})
+    for it.Next() {
+        key := it.Key()
+
+        ttl, err := client.TTL(rc.ctx, key).Result()
         if err == nil && ttl > 0 {
             keysWithTTL++
         }
-    }
-    stats["expiring_keys"] = keysWithTTL
 
-    // Get memory stats for each key
-    var topMemoryKeys []KeyMemoryInfo
+        keyType, err := client.Type(rc.ctx, key).Result()
+        if err == nil {
+            keysByType[keyType]++
+        }
 
-    for _, key := range keys {
-        memory, err := rc.client.MemoryUsage(rc.ctx, key).Result()
+        memory, err := client.MemoryUsage(rc.ctx, key).Result()
         if err == nil {
             topMemoryKeys = append(topMemoryKeys, KeyMemoryInfo{
                 Key:   key,
+                Type:  keyType,
                 Bytes: memory,
             })
         }
     }
+    if err := it.Err(); err != nil {
+        return nil, fmt.Errorf("error scanning keys: %v", err)
+    }
+    stats["expiring_keys"] = keysWithTTL
+    stats["keys_by_type"] = keysByType
 
     // Sort by memory usage and get top 5
     sort.Slice(topMemoryKeys, func(i, j int) bool {
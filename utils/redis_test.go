@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestWithContextFollowsLiveClient(t *testing.T) {
+	rc := NewRedisConnection()
+	clone := rc.WithContext(context.Background())
+	if clone.IsConnected() {
+		t.Fatal("clone of a disconnected connection reports connected")
+	}
+
+	// go-redis only dials on the first command, so no server is needed
+	rc.live.client = redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	if !clone.IsConnected() {
+		t.Fatal("clone does not see the client connected after it was made")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				clone.IsConnected()
+				clone.DB()
+			}
+		}()
+	}
+	rc.Close()
+	wg.Wait()
+
+	if clone.IsConnected() {
+		t.Fatal("clone still sees the client after Close")
+	}
+}
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultScanCount is the COUNT hint used when ScanOptions leaves it unset.
+const defaultScanCount = 1000
+
+// ScanOptions narrows an incremental key scan.
+type ScanOptions struct {
+	Match string // glob pattern passed as MATCH, defaults to "*"
+	Count int64  // COUNT hint per SCAN call
+	Type  string // only return keys of this type (string, hash, list, ...)
+
+	// Filter, when set, drops keys client side after MATCH, for checks a
+	// glob cannot express such as regular expressions
+	Filter func(key string) bool
+}
+
+// GlobEscape escapes the glob metacharacters in s so it matches literally
+// in a SCAN MATCH or KEYS pattern.
+func GlobEscape(s string) string {
+	var glob strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			glob.WriteRune('\\')
+		}
+		glob.WriteRune(r)
+	}
+	return glob.String()
+}
+
+// KeyIterator walks the keyspace with SCAN, one batch at a time, visiting
+// every master when connected to a cluster. It never issues KEYS. Like SCAN
+// itself, a key may be returned more than once.
+type KeyIterator struct {
+	ctx    context.Context
+	opts   ScanOptions
+	nodes  []*redis.Client
+	node   int
+	cursor uint64
+	batch  []string
+	key    string
+	err    error
+
+	// Where the current batch came from and how much of it was consumed,
+	// so Position can describe a resumable point inside a batch
+	batchNode   int
+	batchCursor uint64
+	consumed    int
+	skip        int
+}
+
+// ScanPosition is a point in a key scan that iteration can resume from: the
+// master to scan, the SCAN cursor on it, and how many keys of the batch
+// returned for that cursor were already consumed.
+type ScanPosition struct {
+	Node   int
+	Cursor uint64
+	Skip   int
+}
+
+// ScanKeys returns an iterator over the keys matching opts. Cancelling ctx
+// stops the iteration; the iterator then reports ctx.Err().
+func (rc *RedisConnection) ScanKeys(ctx context.Context, opts ScanOptions) *KeyIterator {
+	if opts.Match == "" {
+		opts.Match = "*"
+	}
+	if opts.Count <= 0 {
+		opts.Count = defaultScanCount
+	}
+
+	it := &KeyIterator{ctx: ctx, opts: opts}
+	client := rc.current()
+	if client == nil {
+		it.err = fmt.Errorf("not connected to Redis")
+		return it
+	}
+
+	var mu sync.Mutex
+	it.err = forEachMaster(ctx, client, func(ctx context.Context, client *redis.Client) error {
+		mu.Lock()
+		it.nodes = append(it.nodes, client)
+		mu.Unlock()
+		return nil
+	})
+	// Masters are visited concurrently above; fix their order so positions
+	// stay meaningful across iterators
+	sort.Slice(it.nodes, func(i, j int) bool {
+		return it.nodes[i].Options().Addr < it.nodes[j].Options().Addr
+	})
+	return it
+}
+
+// ScanKeysFrom is ScanKeys resuming at pos, as returned by Position. If the
+// keyspace changed in between, SCAN guarantees still hold: keys present the
+// whole time are returned at least once.
+func (rc *RedisConnection) ScanKeysFrom(ctx context.Context, opts ScanOptions, pos ScanPosition) *KeyIterator {
+	it := rc.ScanKeys(ctx, opts)
+	it.node, it.cursor, it.skip = pos.Node, pos.Cursor, pos.Skip
+	return it
+}
+
+// Position returns where the iteration would continue from, and whether
+// there can be any keys left there.
+func (it *KeyIterator) Position() (ScanPosition, bool) {
+	if len(it.batch) > 0 {
+		return ScanPosition{Node: it.batchNode, Cursor: it.batchCursor, Skip: it.consumed}, true
+	}
+	return ScanPosition{Node: it.node, Cursor: it.cursor}, it.node < len(it.nodes)
+}
+
+// Next advances to the next key, fetching another SCAN batch when needed.
+func (it *KeyIterator) Next() bool {
+	for it.err == nil {
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+
+		if len(it.batch) > 0 {
+			it.key, it.batch = it.batch[0], it.batch[1:]
+			it.consumed++
+			if it.opts.Filter != nil && !it.opts.Filter(it.key) {
+				continue
+			}
+			return true
+		}
+
+		if it.node >= len(it.nodes) {
+			return false
+		}
+
+		client := it.nodes[it.node]
+		it.batchNode, it.batchCursor, it.consumed = it.node, it.cursor, 0
+		var keys []string
+		var err error
+		if it.opts.Type != "" {
+			keys, it.cursor, err = client.ScanType(it.ctx, it.cursor, it.opts.Match, it.opts.Count, it.opts.Type).Result()
+		} else {
+			keys, it.cursor, err = client.Scan(it.ctx, it.cursor, it.opts.Match, it.opts.Count).Result()
+		}
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.batch = keys
+		if it.skip > 0 {
+			// Resuming inside a batch, drop the keys already seen
+			n := it.skip
+			if n > len(keys) {
+				n = len(keys)
+			}
+			it.batch, it.consumed, it.skip = keys[n:], n, 0
+		}
+		if it.cursor == 0 {
+			// This node is exhausted, move on to the next master
+			it.node++
+		}
+	}
+	return false
+}
+
+// Key returns the current key.
+func (it *KeyIterator) Key() string {
+	return it.key
+}
+
+// Err returns the error, if any, that stopped the iteration.
+func (it *KeyIterator) Err() error {
+	return it.err
+}
+
+// ScanAll collects every key matching opts into a slice, without duplicates.
+func (rc *RedisConnection) ScanAll(ctx context.Context, opts ScanOptions) ([]string, error) {
+	var keys []string
+	seen := make(map[string]struct{})
+
+	it := rc.ScanKeys(ctx, opts)
+	for it.Next() {
+		if _, dup := seen[it.Key()]; dup {
+			continue
+		}
+		seen[it.Key()] = struct{}{}
+		keys = append(keys, it.Key())
+	}
+	return keys, it.Err()
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// sentinelOptions builds the failover client options for a Sentinel-managed master.
+func sentinelOptions(options ConnectOptions) (*redis.FailoverOptions, error) {
+	if options.MasterName == "" {
+		return nil, fmt.Errorf("sentinel connections require a master name")
+	}
+	if len(options.SentinelAddrs) == 0 {
+		return nil, fmt.Errorf("sentinel connections require at least one sentinel address")
+	}
+
+	opts := &redis.FailoverOptions{
+		MasterName:            options.MasterName,
+		SentinelAddrs:         options.SentinelAddrs,
+		SentinelUsername:      options.SentinelUsername,
+		SentinelPassword:      options.SentinelPassword,
+		Username:              options.Username,
+		Password:              options.Password,
+		DB:                    options.DB,
+		DialTimeout:           options.DialTimeout,
+		ReadTimeout:           options.ReadTimeout,
+		WriteTimeout:          options.WriteTimeout,
+		ContextTimeoutEnabled: true,
+	}
+
+	if options.TLS.active() {
+		tlsConfig, err := options.TLS.apply(nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS settings: %v", err)
+		}
+		opts.TLSConfig = tlsConfig
+	}
+
+	return opts, nil
+}
+
+// resolveMaster asks the sentinels which address currently serves the
+// master, reaching them with the same credentials, TLS settings and dialer
+// as the failover client.
+func resolveMaster(ctx context.Context, opts *redis.FailoverOptions) (string, *redis.SentinelClient, error) {
+	var lastErr error
+	for _, addr := range opts.SentinelAddrs {
+		sentinel := redis.NewSentinelClient(&redis.Options{
+			Addr:      addr,
+			Username:  opts.SentinelUsername,
+			Password:  opts.SentinelPassword,
+			TLSConfig: opts.TLSConfig,
+			Dialer:    opts.Dialer,
+		})
+
+		master, err := sentinel.GetMasterAddrByName(ctx, opts.MasterName).Result()
+		if err == nil && len(master) == 2 {
+			return net.JoinHostPort(master[0], master[1]), sentinel, nil
+		}
+
+		sentinel.Close()
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("master '%s' is unknown to the sentinels", opts.MasterName)
+	}
+	return "", nil, lastErr
+}
+
+// watchSentinel reports the resolved master and any failovers for it until
+// ctx is cancelled. opts are those the failover client was created with.
+func (rc *RedisConnection) watchSentinel(ctx context.Context, opts *redis.FailoverOptions) {
+	for ctx.Err() == nil {
+		master, sentinel, err := resolveMaster(ctx, opts)
+		if err != nil {
+			rc.emit("[red]Sentinel:[white] unable to resolve master '%s': %v", opts.MasterName, err)
+		} else {
+			rc.emit("[green]Sentinel:[white] master '%s' is at %s", opts.MasterName, master)
+			rc.followFailovers(ctx, sentinel, opts.MasterName)
+			sentinel.Close()
+		}
+
+		// The subscription dropped (sentinel went away), retry after a pause
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// followFailovers logs failover events for masterName until the subscription ends.
+func (rc *RedisConnection) followFailovers(ctx context.Context, sentinel *redis.SentinelClient, masterName string) {
+	pubsub := sentinel.Subscribe(ctx, "+switch-master", "+failover-state-select-slave", "+odown", "-odown")
+	defer pubsub.Close()
+
+	for {
+		msg, err := pubsub.ReceiveMessage(ctx)
+		if err != nil {
+			return
+		}
+
+		fields := strings.Fields(msg.Payload)
+		switch msg.Channel {
+		case "+switch-master":
+			// <master name> <old ip> <old port> <new ip> <new port>
+			if len(fields) < 5 || fields[0] != masterName {
+				continue
+			}
+			rc.emit("[yellow]Sentinel:[white] failover of '%s' from %s to %s", masterName,
+				net.JoinHostPort(fields[1], fields[2]), net.JoinHostPort(fields[3], fields[4]))
+		default:
+			// <instance type> <name> <ip> <port> ...
+			if len(fields) < 2 || fields[1] != masterName {
+				continue
+			}
+			rc.emit("[yellow]Sentinel:[white] %s %s", msg.Channel, msg.Payload)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SetPageSize is the COUNT hint used when paging a set with SSCAN.
+const SetPageSize = 100
+
+// SetPage is one SSCAN page of a set.
+type SetPage struct {
+	Members []string
+	Next    uint64 // cursor of the following page, 0 after the last one
+	Length  int64  // SCARD of the whole set
+}
+
+// ScanSet reads the page of key's members that starts at cursor, keeping
+// only members matching the glob match (all members when empty).
+func (rc *RedisConnection) ScanSet(key string, cursor uint64, match string) (*SetPage, error) {
+	client := rc.current()
+	if client == nil {
+		return nil, fmt.Errorf("not connected to Redis")
+	}
+	if match == "" {
+		match = "*"
+	}
+
+	length, err := client.SCard(rc.ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	members, next, err := client.SScan(rc.ctx, key, cursor, match, SetPageSize).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	// SSCAN may repeat a member, keep its first appearance
+	page := &SetPage{Next: next, Length: length}
+	seen := make(map[string]struct{}, len(members))
+	for _, member := range members {
+		if _, ok := seen[member]; ok {
+			continue
+		}
+		seen[member] = struct{}{}
+		page.Members = append(page.Members, member)
+	}
+	sort.Strings(page.Members)
+	return page, nil
+}
+
+// AddSetMember adds member with SADD, reporting whether it was new.
+func (rc *RedisConnection) AddSetMember(key, member string) (bool, error) {
+	client := rc.current()
+	if client == nil {
+		return false, fmt.Errorf("not connected to Redis")
+	}
+
+	added, err := client.SAdd(rc.ctx, key, member).Result()
+	return added == 1, err
+}
+
+// RemoveSetMember removes member with SREM. Redis deletes the key with its
+// last member.
+func (rc *RedisConnection) RemoveSetMember(key, member string) error {
+	client := rc.current()
+	if client == nil {
+		return fmt.Errorf("not connected to Redis")
+	}
+
+	removed, err := client.SRem(rc.ctx, key, member).Result()
+	if err == nil && removed == 0 {
+		return fmt.Errorf("member '%s' is not in the set", member)
+	}
+	return err
+}
+
+// ReplaceSetMember swaps member for replacement in one transaction. The
+// key is watched, so if member was removed or the set modified since,
+// nothing is written and ErrKeyChanged is returned. Replacing the only
+// member empties the set on the way, so its TTL is set again.
+func (rc *RedisConnection) ReplaceSetMember(key, member, replacement string) error {
+	client := rc.current()
+	if client == nil {
+		return fmt.Errorf("not connected to Redis")
+	}
+
+	ctx := rc.ctx
+	err := client.Watch(ctx, func(tx *redis.Tx) error {
+		isMember, err := tx.SIsMember(ctx, key, member).Result()
+		if err != nil {
+			return err
+		} else if !isMember {
+			return ErrKeyChanged
+		}
+		ttl, err := tx.PTTL(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.SRem(ctx, key, member)
+			pipe.SAdd(ctx, key, replacement)
+			if ttl > 0 {
+				pipe.PExpire(ctx, key, ttl)
+			}
+			return nil
+		})
+		return err
+	}, key)
+
+	if err == redis.TxFailedErr {
+		return ErrKeyChanged
+	}
+	return err
+}
@@ -0,0 +1,300 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// StreamPageSize is how many stream entries are read at once.
+const StreamPageSize = 50
+
+// PendingPageSize caps how many pending entries of a group are listed.
+const PendingPageSize = 100
+
+// StreamPage is a run of stream entries in ID order, or reverse ID order.
+type StreamPage struct {
+	Entries []StreamEntry
+	Next    string // ID the following page starts at, "" after the last one
+	Length  int64  // XLEN of the whole stream
+}
+
+// StreamInfo summarises a stream as reported by XINFO STREAM. Fields added
+// in later Redis versions are zero on older servers.
+type StreamInfo struct {
+	Length          int64
+	Groups          int64
+	FirstID         string
+	LastID          string
+	LastGeneratedID string
+	MaxDeletedID    string // Redis 7.0+
+	EntriesAdded    int64  // Redis 7.0+
+}
+
+// StreamGroup is a consumer group as reported by XINFO GROUPS.
+type StreamGroup struct {
+	Name            string
+	Consumers       int64
+	Pending         int64
+	LastDeliveredID string
+	EntriesRead     int64 // Redis 7.0+
+	Lag             int64 // Redis 7.0+
+}
+
+// StreamConsumer is a group member as reported by XINFO CONSUMERS.
+type StreamConsumer struct {
+	Name     string
+	Pending  int64
+	Idle     time.Duration // since its last attempted interaction
+	Inactive time.Duration // since its last successful interaction, Redis 7.2+
+}
+
+// PendingEntry is a delivered but unacknowledged entry, from XPENDING.
+type PendingEntry struct {
+	ID         string
+	Consumer   string
+	Idle       time.Duration
+	Deliveries int64
+}
+
+// StreamRange reads the page of key's entries starting at the ID start,
+// or at the first (last with reverse) entry when start is empty. One extra
+// entry is read to find where the following page starts.
+func (rc *RedisConnection) StreamRange(key string, start string, reverse bool) (*StreamPage, error) {
+	client := rc.current()
+	if client == nil {
+		return nil, fmt.Errorf("not connected to Redis")
+	}
+
+	ctx := rc.ctx
+	length, err := client.XLen(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []redis.XMessage
+	if reverse {
+		if start == "" {
+			start = "+"
+		}
+		messages, err = client.XRevRangeN(ctx, key, start, "-", StreamPageSize+1).Result()
+	} else {
+		if start == "" {
+			start = "-"
+		}
+		messages, err = client.XRangeN(ctx, key, start, "+", StreamPageSize+1).Result()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	page := &StreamPage{Length: length}
+	if len(messages) > StreamPageSize {
+		page.Next = messages[StreamPageSize].ID
+		messages = messages[:StreamPageSize]
+	}
+	for _, m := range messages {
+		page.Entries = append(page.Entries, StreamEntry{ID: m.ID, Fields: m.Values})
+	}
+	return page, nil
+}
+
+// GetStreamInfo runs XINFO STREAM on key.
+func (rc *RedisConnection) GetStreamInfo(key string) (*StreamInfo, error) {
+	client := rc.current()
+	if client == nil {
+		return nil, fmt.Errorf("not connected to Redis")
+	}
+
+	info, err := client.XInfoStream(rc.ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	return &StreamInfo{
+		Length:          info.Length,
+		Groups:          info.Groups,
+		FirstID:         info.FirstEntry.ID,
+		LastID:          info.LastEntry.ID,
+		LastGeneratedID: info.LastGeneratedID,
+		MaxDeletedID:    info.MaxDeletedEntryID,
+		EntriesAdded:    info.EntriesAdded,
+	}, nil
+}
+
+// StreamGroups runs XINFO GROUPS on key.
+func (rc *RedisConnection) StreamGroups(key string) ([]StreamGroup, error) {
+	client := rc.current()
+	if client == nil {
+		return nil, fmt.Errorf("not connected to Redis")
+	}
+
+	infos, err := client.XInfoGroups(rc.ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]StreamGroup, len(infos))
+	for i, g := range infos {
+		groups[i] = StreamGroup{
+			Name:            g.Name,
+			Consumers:       g.Consumers,
+			Pending:         g.Pending,
+			LastDeliveredID: g.LastDeliveredID,
+			EntriesRead:     g.EntriesRead,
+			Lag:             g.Lag,
+		}
+	}
+	return groups, nil
+}
+
+// StreamConsumers runs XINFO CONSUMERS for a group of key.
+func (rc *RedisConnection) StreamConsumers(key, group string) ([]StreamConsumer, error) {
+	client := rc.current()
+	if client == nil {
+		return nil, fmt.Errorf("not connected to Redis")
+	}
+
+	infos, err := client.XInfoConsumers(rc.ctx, key, group).Result()
+	if err != nil {
+		return nil, err
+	}
+	consumers := make([]StreamConsumer, len(infos))
+	for i, c := range infos {
+		consumers[i] = StreamConsumer{Name: c.Name, Pending: c.Pending, Idle: c.Idle, Inactive: c.Inactive}
+	}
+	return consumers, nil
+}
+
+// StreamPending lists up to PendingPageSize pending entries of a group,
+// oldest first, optionally only those of one consumer. It also returns how
+// many entries the group has pending in total.
+func (rc *RedisConnection) StreamPending(key, group, consumer string) ([]PendingEntry, int64, error) {
+	client := rc.current()
+	if client == nil {
+		return nil, 0, fmt.Errorf("not connected to Redis")
+	}
+
+	ctx := rc.ctx
+	summary, err := client.XPending(ctx, key, group).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	if summary.Count == 0 {
+		return nil, 0, nil
+	}
+
+	pending, err := client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream:   key,
+		Group:    group,
+		Start:    "-",
+		End:      "+",
+		Count:    PendingPageSize,
+		Consumer: consumer,
+	}).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entries := make([]PendingEntry, len(pending))
+	for i, p := range pending {
+		entries[i] = PendingEntry{ID: p.ID, Consumer: p.Consumer, Idle: p.Idle, Deliveries: p.RetryCount}
+	}
+	return entries, summary.Count, nil
+}
+
+// StreamAdd appends an entry with XADD and returns its ID. An empty id
+// lets Redis generate one; fields alternate names and values.
+func (rc *RedisConnection) StreamAdd(key, id string, fields []string) (string, error) {
+	client := rc.current()
+	if client == nil {
+		return "", fmt.Errorf("not connected to Redis")
+	}
+	if len(fields) == 0 || len(fields)%2 != 0 {
+		return "", fmt.Errorf("an entry needs at least one field and a value for every field")
+	}
+
+	return client.XAdd(rc.ctx, &redis.XAddArgs{Stream: key, ID: id, Values: fields}).Result()
+}
+
+// StreamAck acknowledges entries of a group with XACK, returning how many
+// were pending.
+func (rc *RedisConnection) StreamAck(key, group string, ids ...string) (int64, error) {
+	client := rc.current()
+	if client == nil {
+		return 0, fmt.Errorf("not connected to Redis")
+	}
+
+	return client.XAck(rc.ctx, key, group, ids...).Result()
+}
+
+// StreamClaim transfers pending entries idle for at least minIdle to
+// consumer with XCLAIM, returning the IDs it now owns.
+func (rc *RedisConnection) StreamClaim(key, group, consumer string, minIdle time.Duration, ids ...string) ([]string, error) {
+	client := rc.current()
+	if client == nil {
+		return nil, fmt.Errorf("not connected to Redis")
+	}
+
+	return client.XClaimJustID(rc.ctx, &redis.XClaimArgs{
+		Stream:   key,
+		Group:    group,
+		Consumer: consumer,
+		MinIdle:  minIdle,
+		Messages: ids,
+	}).Result()
+}
+
+// StreamTrim trims key with XTRIM, returning how many entries were
+// removed. A threshold that is a whole number keeps that many entries
+// (MAXLEN); an entry ID removes every entry before it (MINID, Redis 6.2+).
+// With approx Redis may keep some more entries to trim efficiently.
+func (rc *RedisConnection) StreamTrim(key, threshold string, approx bool) (int64, error) {
+	client := rc.current()
+	if client == nil {
+		return 0, fmt.Errorf("not connected to Redis")
+	}
+
+	threshold = strings.TrimSpace(threshold)
+	if maxLen, err := strconv.ParseInt(threshold, 10, 64); err == nil {
+		if maxLen < 0 {
+			return 0, fmt.Errorf("the length to keep cannot be negative")
+		}
+		if approx {
+			return client.XTrimMaxLenApprox(rc.ctx, key, maxLen, 0).Result()
+		}
+		return client.XTrimMaxLen(rc.ctx, key, maxLen).Result()
+	}
+
+	if !IsStreamID(threshold) {
+		return 0, fmt.Errorf("'%s' is neither a length nor an entry ID", threshold)
+	}
+	if approx {
+		return client.XTrimMinIDApprox(rc.ctx, key, threshold, 0).Result()
+	}
+	return client.XTrimMinID(rc.ctx, key, threshold).Result()
+}
+
+// IsStreamID reports whether id is a full stream entry ID such as
+// 1700000000000-0.
+func IsStreamID(id string) bool {
+	ms, seq, ok := strings.Cut(id, "-")
+	if !ok {
+		return false
+	}
+	_, errMs := strconv.ParseUint(ms, 10, 64)
+	_, errSeq := strconv.ParseUint(seq, 10, 64)
+	return errMs == nil && errSeq == nil
+}
+
+// StreamIDTime returns the creation time encoded in an entry ID, which
+// starts with a Unix time in milliseconds.
+func StreamIDTime(id string) (time.Time, bool) {
+	ms, _, _ := strings.Cut(id, "-")
+	millis, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(millis), true
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSOptions holds the TLS settings used when dialing a Redis server.
+type TLSOptions struct {
+	Enabled    bool
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+	ServerName string
+	Insecure   bool
+}
+
+// active reports whether TLS should be used for the connection.
+func (t TLSOptions) active() bool {
+	return t.Enabled || t.CAFile != "" || t.CertFile != "" || t.KeyFile != "" || t.ServerName != "" || t.Insecure
+}
+
+// apply fills in base (which may be nil) with the CA bundle, client
+// certificate, server name and verification settings from t.
+func (t TLSOptions) apply(base *tls.Config) (*tls.Config, error) {
+	cfg := base
+	if cfg == nil {
+		cfg = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA file: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in CA file %s", t.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if t.CertFile != "" || t.KeyFile != "" {
+		if t.CertFile == "" || t.KeyFile == "" {
+			return nil, fmt.Errorf("both a client certificate and a key file are required")
+		}
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if t.ServerName != "" {
+		cfg.ServerName = t.ServerName
+	}
+	if t.Insecure {
+		cfg.InsecureSkipVerify = true
+	}
+
+	return cfg, nil
+}
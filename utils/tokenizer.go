@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SplitArgs splits a command line into arguments the way redis-cli does.
+// Arguments are separated by whitespace and may be quoted: double quoted
+// arguments understand \n, \r, \t, \b, \a, \\, \" and \xNN hex escapes,
+// single quoted arguments only understand \'. A closing quote must be
+// followed by whitespace or the end of the line.
+func SplitArgs(line string) ([]string, error) {
+	var args []string
+	p := 0
+
+	for {
+		// Skip blanks
+		for p < len(line) && isSpace(line[p]) {
+			p++
+		}
+		if p >= len(line) {
+			return args, nil
+		}
+
+		var current strings.Builder
+		inDouble, inSingle := false, false
+
+	token:
+		for {
+			switch {
+			case inDouble:
+				if p >= len(line) {
+					return nil, fmt.Errorf("unbalanced quotes in command")
+				}
+				c := line[p]
+				switch {
+				case c == '\\' && p+3 < len(line) && line[p+1] == 'x' && isHex(line[p+2]) && isHex(line[p+3]):
+					current.WriteByte(hexValue(line[p+2])<<4 | hexValue(line[p+3]))
+					p += 3
+				case c == '\\' && p+1 < len(line):
+					p++
+					switch line[p] {
+					case 'n':
+						current.WriteByte('\n')
+					case 'r':
+						current.WriteByte('\r')
+					case 't':
+						current.WriteByte('\t')
+					case 'b':
+						current.WriteByte('\b')
+					case 'a':
+						current.WriteByte('\a')
+					default:
+						current.WriteByte(line[p])
+					}
+				case c == '"':
+					// The closing quote must be followed by a space or nothing
+					if p+1 < len(line) && !isSpace(line[p+1]) {
+						return nil, fmt.Errorf("closing quote must be followed by a space")
+					}
+					p++
+					break token
+				default:
+					current.WriteByte(c)
+				}
+
+			case inSingle:
+				if p >= len(line) {
+					return nil, fmt.Errorf("unbalanced quotes in command")
+				}
+				c := line[p]
+				switch {
+				case c == '\\' && p+1 < len(line) && line[p+1] == '\'':
+					p++
+					current.WriteByte('\'')
+				case c == '\'':
+					if p+1 < len(line) && !isSpace(line[p+1]) {
+						return nil, fmt.Errorf("closing quote must be followed by a space")
+					}
+					p++
+					break token
+				default:
+					current.WriteByte(c)
+				}
+
+			default:
+				if p >= len(line) {
+					break token
+				}
+				switch c := line[p]; {
+				case isSpace(c):
+					break token
+				case c == '"':
+					inDouble = true
+				case c == '\'':
+					inSingle = true
+				default:
+					current.WriteByte(c)
+				}
+			}
+			p++
+		}
+
+		args = append(args, current.String())
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\v' || c == '\f'
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func hexValue(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	}
+	return c - 'A' + 10
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{"plain", "set foo bar", []string{"set", "foo", "bar"}},
+		{"extra whitespace", "  get \t foo  ", []string{"get", "foo"}},
+		{"empty line", "   ", nil},
+		{"double quotes", `set greeting "hello world"`, []string{"set", "greeting", "hello world"}},
+		{"single quotes", `set greeting 'hello world'`, []string{"set", "greeting", "hello world"}},
+		{"json value", `set user '{"name": "Ada", "age": 36}'`, []string{"set", "user", `{"name": "Ada", "age": 36}`}},
+		{"escaped double quote", `set q "say \"hi\""`, []string{"set", "q", `say "hi"`}},
+		{"escape sequences", `set s "a\nb\tc\\d"`, []string{"set", "s", "a\nb\tc\\d"}},
+		{"hex bytes", `set bin "\x00\xffA\x41"`, []string{"set", "bin", "\x00\xffAA"}},
+		{"invalid hex kept literal", `set s "\xZZ"`, []string{"set", "s", "xZZ"}},
+		{"single quote escape", `set s 'it\'s'`, []string{"set", "s", "it's"}},
+		{"backslash in single quotes", `set s 'a\nb'`, []string{"set", "s", `a\nb`}},
+		{"empty quoted argument", `set s ""`, []string{"set", "s", ""}},
+		{"quote inside token", `set k foo"bar baz"`, []string{"set", "k", "foobar baz"}},
+		{"unicode", `set k "héllo wörld"`, []string{"set", "k", "héllo wörld"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitArgs(tt.line)
+			if err != nil {
+				t.Fatalf("SplitArgs(%q) returned error: %v", tt.line, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitArgs(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitArgsErrors(t *testing.T) {
+	for _, line := range []string{
+		`set k "unterminated`,
+		`set k 'unterminated`,
+		`set k "closed"trailing`,
+		`set k 'closed'trailing`,
+	} {
+		if _, err := SplitArgs(line); err == nil {
+			t.Errorf("SplitArgs(%q) expected an error", line)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ValuePageSize caps how many elements are read from a collection at once.
+const ValuePageSize = 100
+
+// HashField is a single field of a hash value.
+type HashField struct {
+	Field string
+	Value string
+}
+
+// ZMember is a single member of a sorted set value.
+type ZMember struct {
+	Member string
+	Score  float64
+}
+
+// StreamEntry is a single entry of a stream value.
+type StreamEntry struct {
+	ID     string
+	Fields map[string]interface{}
+}
+
+// Value is a Redis value read according to its TYPE. Only the field that
+// matches Type is populated; collections hold at most ValuePageSize elements.
+type Value struct {
+	Type   string
+	String string
+	Hash   []HashField
+	List   []string
+	Set    []string
+	ZSet   []ZMember
+	Stream []StreamEntry
+
+	// Length is the total number of elements (or bytes for strings) on the server
+	Length int64
+}
+
+// Truncated reports whether only part of a collection was read.
+func (v *Value) Truncated() bool {
+	return int64(v.Count()) < v.Length
+}
+
+// Count returns the number of elements held in v.
+func (v *Value) Count() int {
+	switch v.Type {
+	case "hash":
+		return len(v.Hash)
+	case "list":
+		return len(v.List)
+	case "set":
+		return len(v.Set)
+	case "zset":
+		return len(v.ZSet)
+	case "stream":
+		return len(v.Stream)
+	}
+	return int(v.Length)
+}
+
+// Text flattens v into a single string: strings are returned as-is and
+// collections are encoded as JSON (hashes as objects, lists and sets as
+// arrays, sorted sets and streams as arrays of objects).
+func (v *Value) Text() string {
+	var data interface{}
+	switch v.Type {
+	case "string":
+		return v.String
+	case "hash":
+		fields := make(map[string]string, len(v.Hash))
+		for _, f := range v.Hash {
+			fields[f.Field] = f.Value
+		}
+		data = fields
+	case "list":
+		data = v.List
+	case "set":
+		data = v.Set
+	case "zset":
+		members := make([]map[string]interface{}, len(v.ZSet))
+		for i, m := range v.ZSet {
+			members[i] = map[string]interface{}{"member": m.Member, "score": m.Score}
+		}
+		data = members
+	case "stream":
+		entries := make([]map[string]interface{}, len(v.Stream))
+		for i, e := range v.Stream {
+			entries[i] = map[string]interface{}{"id": e.ID, "fields": e.Fields}
+		}
+		data = entries
+	default:
+		return ""
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Sprint(data)
+	}
+	return string(encoded)
+}
+
+// GetKeyType returns the TYPE of key ("none" when it does not exist).
+func (rc *RedisConnection) GetKeyType(key string) (string, error) {
+	client := rc.current()
+	if client == nil {
+		return "", fmt.Errorf("not connected to Redis")
+	}
+
+	return client.Type(rc.ctx, key).Result()
+}
+
+// GetTypedValue detects the type of key and reads its value with the
+// matching command, paging collections to ValuePageSize elements.
+func (rc *RedisConnection) GetTypedValue(key string) (*Value, error) {
+	client := rc.current()
+	if client == nil {
+		return nil, fmt.Errorf("not connected to Redis")
+	}
+
+	ctx := rc.ctx
+	keyType, err := client.Type(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	value := &Value{Type: keyType}
+
+	switch keyType {
+	case "string":
+		value.String, err = client.Get(ctx, key).Result()
+		value.Length = int64(len(value.String))
+
+	case "hash":
+		if value.Length, err = client.HLen(ctx, key).Result(); err != nil {
+			return nil, err
+		}
+		var fields map[string]string
+		if value.Length <= ValuePageSize {
+			fields, err = client.HGetAll(ctx, key).Result()
+		} else {
+			// Large hashes are sampled with HSCAN instead of HGETALL
+			var pairs []string
+			pairs, _, err = client.HScan(ctx, key, 0, "*", ValuePageSize).Result()
+			fields = make(map[string]string, len(pairs)/2)
+			for i := 0; i+1 < len(pairs); i += 2 {
+				fields[pairs[i]] = pairs[i+1]
+			}
+		}
+		for field, v := range fields {
+			value.Hash = append(value.Hash, HashField{Field: field, Value: v})
+		}
+		sort.Slice(value.Hash, func(i, j int) bool {
+			return value.Hash[i].Field < value.Hash[j].Field
+		})
+
+	case "list":
+		if value.Length, err = client.LLen(ctx, key).Result(); err != nil {
+			return nil, err
+		}
+		value.List, err = client.LRange(ctx, key, 0, ValuePageSize-1).Result()
+
+	case "set":
+		if value.Length, err = client.SCard(ctx, key).Result(); err != nil {
+			return nil, err
+		}
+		if value.Length <= ValuePageSize {
+			value.Set, err = client.SMembers(ctx, key).Result()
+		} else {
+			value.Set, _, err = client.SScan(ctx, key, 0, "*", ValuePageSize).Result()
+		}
+		sort.Strings(value.Set)
+
+	case "zset":
+		if value.Length, err = client.ZCard(ctx, key).Result(); err != nil {
+			return nil, err
+		}
+		var members []redis.Z
+		members, err = client.ZRangeWithScores(ctx, key, 0, ValuePageSize-1).Result()
+		for _, m := range members {
+			value.ZSet = append(value.ZSet, ZMember{Member: fmt.Sprint(m.Member), Score: m.Score})
+		}
+
+	case "stream":
+		if value.Length, err = client.XLen(ctx, key).Result(); err != nil {
+			return nil, err
+		}
+		var messages []redis.XMessage
+		messages, err = client.XRangeN(ctx, key, "-", "+", ValuePageSize).Result()
+		for _, m := range messages {
+			value.Stream = append(value.Stream, StreamEntry{ID: m.ID, Fields: m.Values})
+		}
+
+	case "none":
+		return nil, redis.Nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", keyType)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -328,7 +328,8 @@ func (rc *RedisConnection) ServeAnalytics() error {
 
 // Existing helper functions remain the same (GetAnalytics, getBucket, openBrowser)
 func (rc *RedisConnection) GetAnalytics() (*AnalyticsData, error) {
-	if rc.client == nil {
+	client := rc.current()
+	if client == nil {
 		return nil, fmt.Errorf("not connected to Redis")
 	}
 
@@ -345,37 +346,28 @@ func (rc *RedisConnection) GetAnalytics() (*AnalyticsData, error) {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		var cursor uint64
-		var keys []string
-		var err error
 
 		persistentCount, expiringCount := int64(0), int64(0)
 		keyExpirations := make(map[string]int)
 
-		for {
-			keys, cursor, err = rc.client.Scan(ctx, cursor, "*", 1000).Result()
-			if err != nil {
-				mu.Lock()
-				errs = append(errs, fmt.Errorf("keys scan error: %v", err))
-				mu.Unlock()
-				return
-			}
-
-			for _, key := range keys {
-				ttl, _ := rc.client.TTL(ctx, key).Result()
-				if ttl == -1 {
-					persistentCount++
-				} else if ttl > 0 {
-					expiringCount++
-					bucket := getBucket(ttl)
-					keyExpirations[bucket]++
-				}
-			}
-
-			if cursor == 0 {
-				break
+		it := rc.ScanKeys(ctx, ScanOptions{})
+		for it.Next() {
+			ttl, _ := client.TTL(ctx, it.Key()).Result()
+			if ttl == -1 {
+				persistentCount++
+			} else if ttl > 0 {
+				expiringCount++
+				bucket := getBucket(ttl)
+				keyExpirations[bucket]++
 			}
 		}
+		err := it.Err()
+		if err != nil {
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("keys scan error: %v", err))
+			mu.Unlock()
+			return
+		}
 
 		mu.Lock()
 		analytics.PersistentKeys = persistentCount
@@ -388,7 +380,7 @@ func (rc *RedisConnection) GetAnalytics() (*AnalyticsData, error) {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		size, err := rc.client.DBSize(ctx).Result()
+		size, err := dbSize(ctx, client)
 		mu.Lock()
 		if err != nil {
 			errs = append(errs, fmt.Errorf("DB size error: %v", err))
@@ -402,20 +394,16 @@ func (rc *RedisConnection) GetAnalytics() (*AnalyticsData, error) {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		memInfo, err := rc.client.Info(ctx, "memory").Result()
+		memInfo, err := infoByMaster(ctx, client, "memory", "used_memory", "total_system_memory")
 		mu.Lock()
 		if err != nil {
 			errs = append(errs, fmt.Errorf("memory info error: %v", err))
 		} else {
-			lines := strings.Split(memInfo, "\n")
-			for _, line := range lines {
-				if strings.HasPrefix(line, "used_memory:") {
-					fmt.Sscanf(line, "used_memory:%d", &analytics.MemoryUsedBytes)
-				}
-				if strings.HasPrefix(line, "total_system_memory:") {
-					fmt.Sscanf(line, "total_system_memory:%d", &analytics.MemoryTotalBytes)
-				}
+			for _, values := range memInfo {
+				analytics.MemoryUsedBytes += values["used_memory"]
 			}
+			// Masters sharing a machine all report its memory
+			analytics.MemoryTotalBytes = perHostSum(memInfo, "total_system_memory")
 		}
 		mu.Unlock()
 	}()
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ZSetPageSize is how many members of a sorted set are read at once.
+const ZSetPageSize = 100
+
+// ZSetPage is a run of sorted set members in score order.
+type ZSetPage struct {
+	Members []ZMember
+	Offset  int64 // position of the first member within the score range
+	Matched int64 // members within the score range (ZCOUNT)
+	Length  int64 // ZCARD of the whole set
+}
+
+// ZSetRange reads the page of key's members with scores between min and
+// max, skipping offset of them. Bounds use ZRANGEBYSCORE syntax: a number,
+// "(" before a number to exclude it, or -inf and +inf. With desc the
+// highest scores come first.
+func (rc *RedisConnection) ZSetRange(key string, min, max string, offset int64, desc bool) (*ZSetPage, error) {
+	client := rc.current()
+	if client == nil {
+		return nil, fmt.Errorf("not connected to Redis")
+	}
+
+	// The three reads share one round trip
+	ctx := rc.ctx
+	by := &redis.ZRangeBy{Min: min, Max: max, Offset: offset, Count: ZSetPageSize}
+	var length, count *redis.IntCmd
+	var rangeCmd *redis.ZSliceCmd
+	_, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		length = pipe.ZCard(ctx, key)
+		count = pipe.ZCount(ctx, key, min, max)
+		// ZRANGEBYSCORE rather than ZRANGE BYSCORE, which needs Redis 6.2
+		if desc {
+			rangeCmd = pipe.ZRevRangeByScoreWithScores(ctx, key, by)
+		} else {
+			rangeCmd = pipe.ZRangeByScoreWithScores(ctx, key, by)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	members := rangeCmd.Val()
+	matched := count.Val()
+
+	page := &ZSetPage{Offset: offset, Matched: matched, Length: length.Val()}
+	for _, m := range members {
+		page.Members = append(page.Members, ZMember{Member: fmt.Sprint(m.Member), Score: m.Score})
+	}
+	return page, nil
+}
+
+// SetZSetMember sets the score of member with ZADD. With onlyNew the
+// member must not exist yet (ZADD NX); it reports whether it was added.
+func (rc *RedisConnection) SetZSetMember(key, member string, score float64, onlyNew bool) (bool, error) {
+	client := rc.current()
+	if client == nil {
+		return false, fmt.Errorf("not connected to Redis")
+	}
+
+	z := redis.Z{Member: member, Score: score}
+	var added int64
+	var err error
+	if onlyNew {
+		added, err = client.ZAddNX(rc.ctx, key, z).Result()
+	} else {
+		added, err = client.ZAdd(rc.ctx, key, z).Result()
+	}
+	return added == 1, err
+}
+
+// IncrZSetMember adds by to the score of member with ZINCRBY, returning
+// the new score.
+func (rc *RedisConnection) IncrZSetMember(key, member string, by float64) (float64, error) {
+	client := rc.current()
+	if client == nil {
+		return 0, fmt.Errorf("not connected to Redis")
+	}
+
+	return client.ZIncrBy(rc.ctx, key, by, member).Result()
+}
+
+// RemoveZSetMember removes member with ZREM. Redis deletes the key with
+// its last member.
+func (rc *RedisConnection) RemoveZSetMember(key, member string) error {
+	client := rc.current()
+	if client == nil {
+		return fmt.Errorf("not connected to Redis")
+	}
+
+	removed, err := client.ZRem(rc.ctx, key, member).Result()
+	if err == nil && removed == 0 {
+		return fmt.Errorf("member '%s' is not in the sorted set", member)
+	}
+	return err
+}
@@ -0,0 +1,43 @@
+package windows
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// clipboardCommands are tried in order to put text on the system clipboard
+var clipboardCommands = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+}
+
+// copyToClipboard puts text on the clipboard using the first available
+// clipboard tool. Without one it falls back to the OSC 52 escape sequence,
+// which most terminals honour, including over SSH. It returns a short
+// description of how the text was copied.
+func copyToClipboard(text string) (string, error) {
+	for _, command := range clipboardCommands {
+		path, err := exec.LookPath(command[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, command[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("%s failed: %v", command[0], err)
+		}
+		return command[0], nil
+	}
+
+	sequence := fmt.Sprintf("\x1b]52;c;%s\x07", base64.StdEncoding.EncodeToString([]byte(text)))
+	if _, err := os.Stdout.WriteString(sequence); err != nil {
+		return "", err
+	}
+	return "terminal (OSC 52)", nil
+}
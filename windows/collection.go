@@ -0,0 +1,234 @@
+package windows
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Amrit02102004/RediCLI/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// collectionView is the frame shared by the collection editors: a header,
+// a table of elements, a footer for key hints and prompts, and overlays for
+// forms and confirmations, shown in the display pane.
+type collectionView struct {
+	app        *tview.Application
+	pane       *DisplayPane
+	logDisplay *tview.TextView
+
+	header *tview.TextView
+	table  *tview.Table
+	footer *tview.TextView
+	body   *tview.Flex
+	pages  *tview.Pages
+	hints  string
+}
+
+func newCollectionView(app *tview.Application, pane *DisplayPane, logDisplay *tview.TextView, title, hints string) *collectionView {
+	c := &collectionView{
+		app:        app,
+		pane:       pane,
+		logDisplay: logDisplay,
+		header:     tview.NewTextView().SetDynamicColors(true),
+		table:      tview.NewTable().SetFixed(1, 0).SetSelectable(true, false),
+		footer:     tview.NewTextView().SetDynamicColors(true),
+		hints:      hints,
+	}
+	c.body = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(c.header, 1, 0, false).
+		AddItem(c.table, 0, 1, true).
+		AddItem(c.footer, 1, 0, false)
+	c.pages = tview.NewPages().AddPage("body", c.body, true, true)
+	c.pages.SetBorder(true).SetTitle(fmt.Sprintf(" %s ", title))
+	c.footer.SetText(hints)
+	return c
+}
+
+// open shows the view, handing table keys to onKey; Esc closes the view
+func (c *collectionView) open(onKey func(event *tcell.EventKey) *tcell.EventKey) {
+	c.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			c.pane.Close()
+			return nil
+		}
+		return onKey(event)
+	})
+	c.pane.Show(c.pages)
+}
+
+// log writes a line to the Logs pane
+func (c *collectionView) log(format string, args ...interface{}) {
+	c.logDisplay.Write([]byte(fmt.Sprintf(format+"\n", args...)))
+}
+
+// showError reports err in the footer until the next action
+func (c *collectionView) showError(err error) {
+	c.footer.SetText(fmt.Sprintf("[red]%s[white]", tview.Escape(err.Error())))
+}
+
+// setColumns writes the header row of the table
+func (c *collectionView) setColumns(titles ...string) {
+	for i, title := range titles {
+		c.table.SetCell(0, i, tview.NewTableCell(title).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false))
+	}
+}
+
+// selected returns the index of the selected element, or -1 when the
+// table has no elements
+func (c *collectionView) selected(count int) int {
+	row, _ := c.table.GetSelection()
+	if row < 1 || row > count {
+		return -1
+	}
+	return row - 1
+}
+
+// restoreSelection keeps the selection on the same row after a reload,
+// clamped to the rows there are
+func (c *collectionView) restoreSelection(row int) {
+	last := c.table.GetRowCount() - 1
+	if last < 1 {
+		return
+	}
+	if row > last {
+		row = last
+	} else if row < 1 {
+		row = 1
+	}
+	c.table.Select(row, 0)
+}
+
+// closeOverlay removes a form or confirmation and refocuses the table
+func (c *collectionView) closeOverlay() {
+	c.pages.RemovePage("overlay")
+	c.app.SetFocus(c.table)
+}
+
+// showForm floats form over the table, closing it on Esc
+func (c *collectionView) showForm(form *tview.Form, width, height int) {
+	form.SetCancelFunc(c.closeOverlay)
+	c.showOverlay(form, width, height)
+}
+
+// showOverlay floats p, centred, over the table
+func (c *collectionView) showOverlay(p tview.Primitive, width, height int) {
+	c.pages.AddPage("overlay", tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, height, 0, true).
+			AddItem(nil, 0, 1, false), width, 0, true).
+		AddItem(nil, 0, 1, false), true, true)
+	c.app.SetFocus(p)
+}
+
+// showValueForm asks for a single value, which may span lines. save runs
+// in the background through runner and returns the update to apply on the
+// UI goroutine. When that update reports an error it is logged and the
+// form stays open.
+func (c *collectionView) showValueForm(runner *OperationRunner, title, label, value string, save func(conn *utils.RedisConnection, value string) func() error) {
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle(fmt.Sprintf(" %s ", title))
+	input := tview.NewTextArea().SetLabel(label).SetSize(5, 40).SetText(value, true)
+	form.AddFormItem(input)
+	form.AddButton("Save", func() {
+		value := input.GetText()
+		runner.Start(strings.ToLower(title), func(conn *utils.RedisConnection) func() {
+			apply := save(conn, value)
+			return func() {
+				if err := apply(); err != nil {
+					if !isCancelled(err) {
+						c.log("[red]Error:[white] %s", tview.Escape(err.Error()))
+					}
+					return
+				}
+				c.closeOverlay()
+			}
+		})
+	})
+	form.AddButton("Cancel", c.closeOverlay)
+	c.showForm(form, 60, 11)
+}
+
+// confirm asks a yes/no question over the table, running yes on Yes
+func (c *collectionView) confirm(text string, yes func()) {
+	modal := tview.NewModal().
+		SetText(tview.Escape(text)).
+		AddButtons([]string{"Yes", "No"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			c.closeOverlay()
+			if buttonLabel == "Yes" {
+				yes()
+			}
+		})
+	c.pages.AddPage("overlay", modal, true, true)
+	c.app.SetFocus(modal)
+}
+
+// prompt shows a one-line input in place of the footer; done gets the text
+// on Enter and is skipped on Esc
+func (c *collectionView) prompt(label, text string, done func(text string)) {
+	input := tview.NewInputField().SetLabel(label).SetText(text)
+	input.SetDoneFunc(func(key tcell.Key) {
+		c.body.RemoveItem(input)
+		c.body.AddItem(c.footer, 1, 0, false)
+		c.app.SetFocus(c.table)
+		if key == tcell.KeyEnter {
+			done(input.GetText())
+		}
+	})
+	c.body.RemoveItem(c.footer)
+	c.body.AddItem(input, 1, 0, true)
+	c.app.SetFocus(input)
+}
+
+// cursorPages remembers where each visited page of an HSCAN or SSCAN
+// started, so pages can be walked back as well as forward.
+type cursorPages struct {
+	starts []uint64
+	next   uint64 // cursor of the following page, 0 after the last one
+}
+
+func newCursorPages() *cursorPages {
+	return &cursorPages{starts: []uint64{0}}
+}
+
+func (p *cursorPages) reset()          { p.starts, p.next = []uint64{0}, 0 }
+func (p *cursorPages) current() uint64 { return p.starts[len(p.starts)-1] }
+func (p *cursorPages) number() int     { return len(p.starts) }
+func (p *cursorPages) hasNext() bool   { return p.next != 0 }
+func (p *cursorPages) hasPrev() bool   { return len(p.starts) > 1 }
+func (p *cursorPages) forward()        { p.starts = append(p.starts, p.next) }
+func (p *cursorPages) back()           { p.starts = p.starts[:len(p.starts)-1] }
+
+// cellText flattens a value for a single table cell: binary data is shown
+// quoted and line breaks as ⏎
+func cellText(s string) string {
+	if !utils.IsReadable([]byte(s)) {
+		quoted := strconv.Quote(s)
+		return tview.Escape(quoted[1 : len(quoted)-1])
+	}
+	return tview.Escape(strings.NewReplacer("\r\n", "⏎", "\n", "⏎", "\t", " ").Replace(s))
+}
+
+// parseElementTTL reads a TTL typed in an editor: seconds, or a duration
+// such as 90s or 2h. Empty or 0 means no TTL.
+func parseElementTTL(text string) (time.Duration, error) {
+	text = strings.TrimSpace(text)
+	if text == "" || text == "0" {
+		return 0, nil
+	}
+	if seconds, err := strconv.ParseFloat(text, 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	ttl, err := time.ParseDuration(text)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("invalid TTL '%s', use seconds or a duration such as 90s", text)
+	}
+	return ttl, nil
+}
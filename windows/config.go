@@ -2,19 +2,142 @@ package windows
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Amrit02102004/RediCLI/utils"
 	"github.com/rivo/tview"
 )
 
 type ConnectionConfig struct {
-	Name string `json:"name"`
-	Host string `json:"host"`
-	Port string `json:"port"`
+	Name     string `json:"name"`
+	Type     string `json:"type,omitempty"`
+	Host     string `json:"host"`
+	Port     string `json:"port"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	DB       int    `json:"db,omitempty"`
+	// AskPasswords marks a connection saved without its passwords, which
+	// are asked for on connect instead
+	AskPasswords bool `json:"ask_passwords,omitempty"`
+
+	TLS           bool   `json:"tls,omitempty"`
+	TLSCAFile     string `json:"tls_ca_file,omitempty"`
+	TLSCertFile   string `json:"tls_cert_file,omitempty"`
+	TLSKeyFile    string `json:"tls_key_file,omitempty"`
+	TLSServerName string `json:"tls_server_name,omitempty"`
+	TLSInsecure   bool   `json:"tls_insecure,omitempty"`
+
+	MasterName       string   `json:"master_name,omitempty"`
+	SentinelAddrs    []string `json:"sentinel_addrs,omitempty"`
+	SentinelUsername string   `json:"sentinel_username,omitempty"`
+	SentinelPassword string   `json:"sentinel_password,omitempty"`
+
+	ClusterAddrs []string `json:"cluster_addrs,omitempty"`
+
+	SSHHost           string `json:"ssh_host,omitempty"`
+	SSHUser           string `json:"ssh_user,omitempty"`
+	SSHKeyFile        string `json:"ssh_key_file,omitempty"`
+	SSHKnownHostsFile string `json:"ssh_known_hosts_file,omitempty"`
+
+	// Timeouts are stored as Go duration strings such as "5s" or "500ms"
+	DialTimeout  string `json:"dial_timeout,omitempty"`
+	ReadTimeout  string `json:"read_timeout,omitempty"`
+	WriteTimeout string `json:"write_timeout,omitempty"`
+}
+
+// parseTimeout parses an optional timeout, treating an empty value as unset
+func parseTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid timeout %q, use a duration such as 5s or 500ms", value)
+	}
+	return d, nil
+}
+
+// ConnectOptions converts a saved connection into the options used to dial Redis
+func (c ConnectionConfig) ConnectOptions() utils.ConnectOptions {
+	options := utils.ConnectOptions{
+		Type:     c.Type,
+		Host:     c.Host,
+		Port:     c.Port,
+		Username: c.Username,
+		Password: c.Password,
+		DB:       c.DB,
+		TLS: utils.TLSOptions{
+			Enabled:    c.TLS,
+			CAFile:     c.TLSCAFile,
+			CertFile:   c.TLSCertFile,
+			KeyFile:    c.TLSKeyFile,
+			ServerName: c.TLSServerName,
+			Insecure:   c.TLSInsecure,
+		},
+		MasterName:       c.MasterName,
+		SentinelAddrs:    c.SentinelAddrs,
+		SentinelUsername: c.SentinelUsername,
+		SentinelPassword: c.SentinelPassword,
+		ClusterAddrs:     c.ClusterAddrs,
+		SSH: utils.SSHOptions{
+			Host:           c.SSHHost,
+			User:           c.SSHUser,
+			KeyFile:        c.SSHKeyFile,
+			KnownHostsFile: c.SSHKnownHostsFile,
+		},
+	}
+	// Invalid timeouts are rejected when the connection is saved, so
+	// anything unparsable here falls back to the go-redis defaults
+	options.DialTimeout, _ = parseTimeout(c.DialTimeout)
+	options.ReadTimeout, _ = parseTimeout(c.ReadTimeout)
+	options.WriteTimeout, _ = parseTimeout(c.WriteTimeout)
+	return options
+}
+
+// askPasswords asks for the passwords config was saved without, then calls
+// connect with them filled in. Connections saved with their passwords go
+// straight to connect.
+func askPasswords(pane *DisplayPane, config ConnectionConfig, connect func(config ConnectionConfig)) {
+	if !config.AskPasswords {
+		connect(config)
+		return
+	}
+
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle(fmt.Sprintf(" Connect to %s ", tview.Escape(config.Name)))
+	form.AddPasswordField("Password", "", 24, '*', func(text string) {
+		config.Password = text
+	})
+	if config.Type == utils.ConnectionSentinel {
+		form.AddPasswordField("Sentinel Pass", "", 24, '*', func(text string) {
+			config.SentinelPassword = text
+		})
+	}
+	form.AddButton("Connect", func() {
+		pane.Close()
+		connect(config)
+	})
+	form.AddButton("Cancel", pane.Close)
+	form.SetCancelFunc(pane.Close)
+	pane.Show(form)
+}
+
+// connectionErrorMessage turns a connection error into a readable log line,
+// calling out authentication failures separately from network problems
+func connectionErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, utils.ErrAuthRequired):
+		return "[red]Authentication required:[white] the server needs a password, set one on the connection\n"
+	case errors.Is(err, utils.ErrWrongPassword):
+		return "[red]Authentication failed:[white] check the username and password of the connection\n"
+	}
+	return fmt.Sprintf("[red]Connection failed: %v[white]\n", err)
 }
 
 func getConnectionsFilePath() string {
@@ -62,7 +185,7 @@ func saveConnection(config ConnectionConfig) error {
 		return err
 	}
 
-	return os.WriteFile(filePath, updatedData, 0644)
+	return os.WriteFile(filePath, updatedData, 0600)
 }
 
 func GetConnections() ([]ConnectionConfig, error) {
@@ -106,8 +229,39 @@ func FormatConnectionsList(connections []ConnectionConfig) string {
 	var builder strings.Builder
 	builder.WriteString("[yellow]Saved Redis Connections:[white]\n\n")
 	for _, conn := range connections {
-		builder.WriteString(fmt.Sprintf("• [green]%s[white]: %s:%s\n",
-			conn.Name, conn.Host, conn.Port))
+		user, db := "", ""
+		if conn.Username != "" {
+			user = conn.Username + "@"
+		}
+		if conn.DB != 0 {
+			db = fmt.Sprintf(" (db %d)", conn.DB)
+		}
+		if conn.TLS || conn.TLSCAFile != "" || conn.TLSCertFile != "" {
+			db += " [TLS]"
+		}
+		if conn.Type == utils.ConnectionSentinel {
+			builder.WriteString(fmt.Sprintf("• [green]%s[white]: sentinel %s%s via %s%s\n",
+				conn.Name, user, conn.MasterName, strings.Join(conn.SentinelAddrs, ", "), db))
+			continue
+		}
+		if conn.Type == utils.ConnectionCluster {
+			db += " [cluster]"
+			if len(conn.ClusterAddrs) > 0 {
+				db += " + " + strings.Join(conn.ClusterAddrs, ", ")
+			}
+		}
+		if conn.SSHHost != "" {
+			db += fmt.Sprintf(" via ssh %s@%s", conn.SSHUser, conn.SSHHost)
+		}
+		if conn.AskPasswords {
+			db += " (asks for passwords)"
+		}
+		if conn.Type == utils.ConnectionUnix {
+			builder.WriteString(fmt.Sprintf("• [green]%s[white]: unix %s%s\n", conn.Name, conn.Host, db))
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("• [green]%s[white]: %s%s:%s%s\n",
+			conn.Name, user, conn.Host, conn.Port, db))
 	}
 	return builder.String()
 }
@@ -147,19 +301,19 @@ func deleteConnectionByName(name string) error {
 		return err
 	}
 
-	return os.WriteFile(filePath, updatedData, 0644)
+	return os.WriteFile(filePath, updatedData, 0600)
 }
 
 func deleteAllConnections() error {
 	filePath := getConnectionsFilePath()
-	return os.WriteFile(filePath, []byte("[]"), 0644)
+	return os.WriteFile(filePath, []byte("[]"), 0600)
 }
 
 func RefreshData(logDisplay *tview.TextView, kvDisplay *tview.TextView, redis *utils.RedisConnection) {
 	if redis.IsConnected() {
-		_, err := redis.GetAllKeys()
-		if err != nil {
-			logDisplay.Write([]byte(fmt.Sprintf("Error fetching keys: %v\n", err)))
+		// A ping is enough to surface connection problems without walking the keyspace
+		if err := redis.Ping(); err != nil {
+			logDisplay.Write([]byte(fmt.Sprintf("Error reaching Redis: %v\n", err)))
 			return
 		}
 	}
@@ -169,13 +323,33 @@ func ConnectionForm(app *tview.Application, logDisplay *tview.TextView, redis *u
 	// Create the form
 	form := tview.NewForm()
 
-	var name, host, port string
+	var name, host, port, username, password, db string
+	var caFile, certFile, keyFile, serverName string
+	var useTLS, insecure bool
+	var connType, masterName, sentinelAddrs, sentinelUsername, sentinelPassword string
+	var clusterAddrs string
+	var sshHost, sshUser, sshKeyFile, sshKnownHosts string
+	var dialTimeout, readTimeout, writeTimeout string
+	savePasswords := true
 
 	form.AddInputField("Connection Name*", "", 18, nil, func(text string) {
 		name = text
 	})
 
-	form.AddInputField("Host/URL*    ", "", 18, nil, func(text string) {
+	form.AddDropDown("Type", []string{"Standalone", "Sentinel", "Cluster", "Unix Socket"}, 0, func(option string, index int) {
+		switch option {
+		case "Unix Socket":
+			connType = utils.ConnectionUnix
+		case "Sentinel":
+			connType = utils.ConnectionSentinel
+		case "Cluster":
+			connType = utils.ConnectionCluster
+		default:
+			connType = utils.ConnectionStandalone
+		}
+	})
+
+	form.AddInputField("Host/URL/Path*", "", 18, nil, func(text string) {
 		host = text
 	})
 
@@ -183,11 +357,107 @@ func ConnectionForm(app *tview.Application, logDisplay *tview.TextView, redis *u
 		port = text
 	})
 
+	form.AddInputField("Username", "", 18, nil, func(text string) {
+		username = text
+	})
+
+	form.AddPasswordField("Password", "", 18, '*', func(text string) {
+		password = text
+	})
+
+	form.AddInputField("DB     ", "", 18, tview.InputFieldInteger, func(text string) {
+		db = text
+	})
+
+	// Sentinel section
+	form.AddInputField("Master Name", "", 18, nil, func(text string) {
+		masterName = text
+	})
+
+	form.AddInputField("Sentinels", "", 18, nil, func(text string) {
+		sentinelAddrs = text
+	})
+
+	form.AddInputField("Sentinel User", "", 18, nil, func(text string) {
+		sentinelUsername = text
+	})
+
+	form.AddPasswordField("Sentinel Pass", "", 18, '*', func(text string) {
+		sentinelPassword = text
+	})
+
+	form.AddCheckbox("Save Passwords", true, func(checked bool) {
+		savePasswords = checked
+	})
+
+	// Cluster section
+	form.AddInputField("Cluster Nodes", "", 18, nil, func(text string) {
+		clusterAddrs = text
+	})
+
+	// TLS section
+	form.AddCheckbox("Use TLS", false, func(checked bool) {
+		useTLS = checked
+	})
+
+	form.AddInputField("CA File", "", 18, nil, func(text string) {
+		caFile = text
+	})
+
+	form.AddInputField("Cert File", "", 18, nil, func(text string) {
+		certFile = text
+	})
+
+	form.AddInputField("Key File", "", 18, nil, func(text string) {
+		keyFile = text
+	})
+
+	form.AddInputField("Server Name", "", 18, nil, func(text string) {
+		serverName = text
+	})
+
+	form.AddCheckbox("Skip Verify", false, func(checked bool) {
+		insecure = checked
+	})
+
+	// SSH tunnel section
+	form.AddInputField("SSH Host", "", 18, nil, func(text string) {
+		sshHost = text
+	})
+
+	form.AddInputField("SSH User", "", 18, nil, func(text string) {
+		sshUser = text
+	})
+
+	form.AddInputField("SSH Key File", "", 18, nil, func(text string) {
+		sshKeyFile = text
+	})
+
+	form.AddInputField("Known Hosts", "", 18, nil, func(text string) {
+		sshKnownHosts = text
+	})
+
+	// Timeouts section
+	form.AddInputField("Dial Timeout", "", 18, nil, func(text string) {
+		dialTimeout = strings.TrimSpace(text)
+	})
+
+	form.AddInputField("Read Timeout", "", 18, nil, func(text string) {
+		readTimeout = strings.TrimSpace(text)
+	})
+
+	form.AddInputField("Write Timeout", "", 18, nil, func(text string) {
+		writeTimeout = strings.TrimSpace(text)
+	})
+
 	// Create Flex layout
 	flex := tview.NewFlex().SetDirection(tview.FlexRow)
 
 	// Add the form items directly into Flex
 	flex.AddItem(form, 0, 1, false)
+	flex.AddItem(tview.NewTextView().SetDynamicColors(true).SetText(fmt.Sprintf(
+		"[gray]Saved passwords are stored as plain text in %s. Untick Save Passwords to be asked for them on connect instead.[white]",
+		tview.Escape(getConnectionsFilePath()))), 2, 0, false)
 
 	// Add other components (buttons, text views)
 	form.AddButton("Save & Connect", func() {
@@ -199,6 +469,45 @@ func ConnectionForm(app *tview.Application, logDisplay *tview.TextView, redis *u
 			return
 		}
 
+		// Sentinels are given as a comma separated host:port list
+		var sentinels []string
+		for _, addr := range strings.Split(sentinelAddrs, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				sentinels = append(sentinels, addr)
+			}
+		}
+		if connType == utils.ConnectionSentinel && (masterName == "" || len(sentinels) == 0) {
+			logDisplay.Write([]byte("[red]Error: Sentinel connections need a Master Name and Sentinels[white]\n"))
+			return
+		}
+		// Extra cluster seed nodes use the same host:port list format
+		var clusterNodes []string
+		for _, addr := range strings.Split(clusterAddrs, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				clusterNodes = append(clusterNodes, addr)
+			}
+		}
+		if connType == utils.ConnectionCluster && db != "" && db != "0" {
+			logDisplay.Write([]byte("[red]Error: Redis Cluster only supports DB 0[white]\n"))
+			return
+		}
+
+		if connType == utils.ConnectionUnix && host == "" {
+			logDisplay.Write([]byte("[red]Error: Unix socket connections need the socket path as Host[white]\n"))
+			return
+		}
+		if sshHost != "" && (sshUser == "" || sshKeyFile == "") {
+			logDisplay.Write([]byte("[red]Error: SSH tunnels need an SSH User and SSH Key File[white]\n"))
+			return
+		}
+
+		for _, timeout := range []string{dialTimeout, readTimeout, writeTimeout} {
+			if _, err := parseTimeout(timeout); err != nil {
+				logDisplay.Write([]byte(fmt.Sprintf("[red]Error: %v[white]\n", err)))
+				return
+			}
+		}
+
 		// Default to localhost if no input
 		if host == "" {
 			host = "localhost"
@@ -207,24 +516,71 @@ func ConnectionForm(app *tview.Application, logDisplay *tview.TextView, redis *u
 			port = "6379"
 		}
 
+		dbIndex := 0
+		if db != "" {
+			var err error
+			dbIndex, err = strconv.Atoi(db)
+			if err != nil || dbIndex < 0 {
+				logDisplay.Write([]byte("[red]Error: DB must be a non-negative number[white]\n"))
+				return
+			}
+		}
+
 		// Create connection config
 		config := ConnectionConfig{
-			Name: name,
-			Host: host,
-			Port: port,
+			Name:     name,
+			Type:     connType,
+			Host:     host,
+			Port:     port,
+			Username: username,
+			Password: password,
+			DB:       dbIndex,
+
+			TLS:           useTLS,
+			TLSCAFile:     caFile,
+			TLSCertFile:   certFile,
+			TLSKeyFile:    keyFile,
+			TLSServerName: serverName,
+			TLSInsecure:   insecure,
+
+			MasterName:       masterName,
+			SentinelAddrs:    sentinels,
+			SentinelUsername: sentinelUsername,
+			SentinelPassword: sentinelPassword,
+
+			ClusterAddrs: clusterNodes,
+
+			SSHHost:           sshHost,
+			SSHUser:           sshUser,
+			SSHKeyFile:        sshKeyFile,
+			SSHKnownHostsFile: sshKnownHosts,
+
+			DialTimeout:  dialTimeout,
+			ReadTimeout:  readTimeout,
+			WriteTimeout: writeTimeout,
 		}
 
-		// Save connection
-		err := saveConnection(config)
+		if (certFile == "") != (keyFile == "") {
+			logDisplay.Write([]byte("[red]Error: Cert File and Key File must be set together[white]\n"))
+			return
+		}
+
+		// Save connection, without its passwords when they are to be asked
+		saved := config
+		if !savePasswords && (password != "" || sentinelPassword != "") {
+			saved.Password, saved.SentinelPassword = "", ""
+			saved.AskPasswords = true
+		}
+		err := saveConnection(saved)
 		if err != nil {
 			logDisplay.Write([]byte(fmt.Sprintf("[red]Error saving connection: %v[white]\n", err)))
 			return
 		}
 
 		// Attempt to connect
-		err = redis.Connect(host, port)
+		err = redis.ConnectWithOptions(config.ConnectOptions())
 		if err != nil {
-			logDisplay.Write([]byte(fmt.Sprintf("[red]Connection failed: %v[white]\n", err)))
+			logDisplay.Write([]byte(connectionErrorMessage(err)))
 			return
 		}
 
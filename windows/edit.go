@@ -0,0 +1,159 @@
+package windows
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Amrit02102004/RediCLI/utils"
+	"github.com/rivo/tview"
+)
+
+// editorCommand returns the user's editor from $VISUAL or $EDITOR, split
+// into a program and its arguments (e.g. "code --wait")
+func editorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(name)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// runEditor opens path in the user's editor on the terminal
+func runEditor(path string) error {
+	command := editorCommand()
+	cmd := exec.Command(command[0], append(command[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %v", command[0], err)
+	}
+	return nil
+}
+
+// askReopen prints why an edit was rejected and asks whether to fix it
+func askReopen(problem error) bool {
+	fmt.Fprintf(os.Stderr, "\n%v\nRe-open the editor to fix it? [Y/n] ", problem)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "" || answer == "y" || answer == "yes"
+}
+
+// logEditError reports a failed edit in the Logs pane
+func logEditError(logDisplay *tview.TextView, format string, args ...interface{}) {
+	logDisplay.Write([]byte(fmt.Sprintf("[red]Edit Error:[white] "+format+"\n", args...)))
+}
+
+// editTimeout bounds reading the key to edit and writing it back
+const editTimeout = 30 * time.Second
+
+// EditValue opens key in $EDITOR with the application suspended. Hashes
+// and sorted sets are edited as JSON objects, lists and sets as arrays.
+// The edit is validated when the editor exits, offering to re-open it, and
+// is written back keeping the TTL unless the key changed in the meantime.
+// Rejected edits are kept in a temporary file so no work is lost. The key
+// is read and written through runner, with a deadline, so a slow server
+// does not freeze the TUI.
+func EditValue(app *tview.Application, runner *OperationRunner, logDisplay *tview.TextView, key string) {
+	runner.Start("edit", func(conn *utils.RedisConnection) func() {
+		ctx, cancel := context.WithTimeout(conn.Context(), editTimeout)
+		defer cancel()
+		session, err := conn.WithContext(ctx).OpenEdit(key)
+		return func() {
+			if isCancelled(err) {
+				return
+			} else if err != nil {
+				logEditError(logDisplay, "%v", tview.Escape(err.Error()))
+				return
+			}
+			editSession(app, runner, logDisplay, key, session)
+		}
+	})
+}
+
+// editSession runs the editor on session and saves the result. It runs on
+// the UI goroutine.
+func editSession(app *tview.Application, runner *OperationRunner, logDisplay *tview.TextView, key string, session *utils.EditSession) {
+	file, err := os.CreateTemp("", "redicli-*"+session.Extension())
+	if err != nil {
+		logEditError(logDisplay, "%v", err)
+		return
+	}
+	path := file.Name()
+	_, err = file.Write(session.Text)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		logEditError(logDisplay, "%v", err)
+		return
+	}
+
+	var edited []byte
+	var editErr error
+	app.Suspend(func() {
+		for {
+			if editErr = runEditor(path); editErr != nil {
+				return
+			}
+			if edited, editErr = os.ReadFile(path); editErr != nil {
+				return
+			}
+			if bytes.Equal(edited, session.Text) {
+				return
+			}
+			editErr = session.Validate(edited)
+			if editErr == nil || !askReopen(editErr) {
+				return
+			}
+		}
+	})
+
+	keep := func(reason string) {
+		logEditError(logDisplay, "%s, your edit is kept in %s", tview.Escape(reason), tview.Escape(path))
+	}
+
+	switch {
+	case editErr != nil && edited == nil:
+		os.Remove(path)
+		logEditError(logDisplay, "%v", tview.Escape(editErr.Error()))
+	case editErr != nil:
+		keep(editErr.Error())
+	case bytes.Equal(edited, session.Text):
+		os.Remove(path)
+		logDisplay.Write([]byte(fmt.Sprintf("[yellow]No changes made to '%s'[white]\n", tview.Escape(key))))
+	default:
+		started := runner.Start("save", func(conn *utils.RedisConnection) func() {
+			ctx, cancel := context.WithTimeout(conn.Context(), editTimeout)
+			defer cancel()
+			err := conn.WithContext(ctx).SaveEdit(session, edited)
+			return func() {
+				if errors.Is(err, utils.ErrKeyChanged) {
+					keep(fmt.Sprintf("'%s' was modified while you were editing it, nothing was saved", key))
+					return
+				} else if err != nil {
+					keep(err.Error())
+					return
+				}
+				os.Remove(path)
+				logDisplay.Write([]byte(fmt.Sprintf("[green]Saved '%s' (%s), TTL kept[white]\n", tview.Escape(key), session.Type)))
+			}
+		})
+		if !started {
+			keep("another operation is running, nothing was saved")
+		}
+	}
+}
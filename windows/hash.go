@@ -0,0 +1,285 @@
+package windows
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Amrit02102004/RediCLI/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// HashEditor lists the fields of a hash in the display pane, an HSCAN page
+// at a time, with actions to add, edit and delete fields. On servers with
+// field expiration (Redis 7.4+) it shows each field's TTL and can set it.
+// Redis is read and written through runner, so slow calls can be cancelled.
+func HashEditor(app *tview.Application, runner *OperationRunner, logDisplay *tview.TextView, pane *DisplayPane, key string) {
+	view := newCollectionView(app, pane, logDisplay, "Hash "+tview.Escape(key),
+		"[gray]a add · Enter/e edit · x delete · n/p page · / match · r reload · Esc close[white]")
+
+	var page *utils.HashPage
+	cursors := newCursorPages()
+	match := ""
+
+	render := func() {
+		view.table.Clear()
+		if page.TTLs != nil {
+			view.setColumns("Field", "Value", "TTL")
+		} else {
+			view.setColumns("Field", "Value")
+		}
+
+		for i, f := range page.Fields {
+			view.table.SetCell(i+1, 0, tview.NewTableCell(cellText(f.Field)).
+				SetTextColor(tcell.ColorGreen).
+				SetMaxWidth(30))
+			view.table.SetCell(i+1, 1, tview.NewTableCell(cellText(f.Value)).
+				SetMaxWidth(60).
+				SetExpansion(1))
+			if page.TTLs != nil {
+				cell := tview.NewTableCell("-").SetAlign(tview.AlignRight)
+				if ttl := page.TTLs[i]; ttl >= 0 {
+					cell.SetText(formatTTL(ttl)).SetTextColor(ttlColor(ttl))
+				}
+				view.table.SetCell(i+1, 2, cell)
+			}
+		}
+
+		info := fmt.Sprintf("[yellow]%d fields[white] · page %d", page.Length, cursors.number())
+		if cursors.hasNext() {
+			info += " (n for more)"
+		}
+		if match != "" {
+			info += fmt.Sprintf(" · matching [green]%s[white]", tview.Escape(match))
+		}
+		if page.Length == 0 {
+			info += " · [red]the key no longer exists[white]"
+		} else if page.TTLs == nil {
+			info += " · [gray]field TTLs need Redis 7.4+[white]"
+		}
+		view.header.SetText(info)
+	}
+
+	// load reads the page at the current cursor in the background, keeping
+	// the selected row, then calls then (when set) with the outcome. It
+	// reports whether the read could be started.
+	load := func(then func(err error)) bool {
+		cursor, match := cursors.current(), match
+		return runner.Start("hash page", func(conn *utils.RedisConnection) func() {
+			next, err := conn.ScanHash(key, cursor, match)
+			return func() {
+				if err == nil {
+					row, _ := view.table.GetSelection()
+					page = next
+					cursors.next = page.Next
+					render()
+					view.restoreSelection(row)
+					view.footer.SetText(view.hints)
+				} else if !isCancelled(err) {
+					view.showError(err)
+				}
+				if then != nil {
+					then(err)
+				}
+			}
+		})
+	}
+
+	selectedField := func() (utils.HashField, time.Duration, bool) {
+		i := view.selected(len(page.Fields))
+		if i < 0 {
+			return utils.HashField{}, -1, false
+		}
+		ttl := time.Duration(-1)
+		if page.TTLs != nil {
+			ttl = page.TTLs[i]
+		}
+		return page.Fields[i], ttl, true
+	}
+
+	// showFieldForm adds a field, or edits one when existing is set
+	showFieldForm := func(existing *utils.HashField, ttl time.Duration) {
+		form := tview.NewForm()
+		title := " Add field "
+		if existing != nil {
+			title = fmt.Sprintf(" Edit field %s ", cellText(existing.Field))
+		}
+		form.SetBorder(true).SetTitle(title)
+
+		fieldInput := tview.NewInputField().SetLabel("Field: ").SetFieldWidth(40)
+		valueInput := tview.NewTextArea().SetLabel("Value: ").SetSize(5, 40)
+		ttlInput := tview.NewInputField().SetLabel("TTL (s): ").SetFieldWidth(12)
+		if existing != nil {
+			fieldInput.SetText(existing.Field).SetDisabled(true)
+			valueInput.SetText(existing.Value, false)
+			if ttl > 0 {
+				ttlInput.SetText(strconv.FormatInt(int64(math.Ceil(ttl.Seconds())), 10))
+			}
+		}
+		originalTTL := ttlInput.GetText()
+
+		form.AddFormItem(fieldInput)
+		form.AddFormItem(valueInput)
+		if page.TTLs != nil {
+			form.AddFormItem(ttlInput)
+		}
+
+		form.AddButton("Save", func() {
+			field, value := fieldInput.GetText(), valueInput.GetText()
+			if field == "" {
+				view.log("[red]Error:[white] the field name is required")
+				return
+			}
+			fieldTTL, err := parseElementTTL(ttlInput.GetText())
+			if err != nil {
+				view.log("[red]Error:[white] %v", err)
+				return
+			}
+
+			setTTL := page.TTLs != nil && ttlInput.GetText() != originalTTL
+
+			runner.Start("save field", func(conn *utils.RedisConnection) func() {
+				added := true
+				var err, ttlErr error
+				if existing == nil {
+					added, err = conn.AddHashField(key, field, value)
+				} else {
+					err = conn.SetHashField(key, field, value)
+				}
+				if err == nil && added && setTTL {
+					ttlErr = conn.ExpireHashField(key, field, fieldTTL)
+				}
+
+				return func() {
+					switch {
+					case isCancelled(err):
+						return
+					case err != nil && existing == nil:
+						view.log("[red]Error adding field:[white] %v", err)
+						return
+					case err != nil:
+						view.log("[red]Error saving field:[white] %v", err)
+						return
+					case !added:
+						view.log("[red]Error:[white] field '%s' already exists, edit it instead", tview.Escape(field))
+						return
+					case existing == nil:
+						view.log("[green]Added field '%s' to '%s'[white]", tview.Escape(field), tview.Escape(key))
+					default:
+						view.log("[green]Saved field '%s' of '%s'[white]", tview.Escape(field), tview.Escape(key))
+					}
+
+					if setTTL {
+						if ttlErr != nil {
+							view.log("[red]Error setting the TTL of '%s':[white] %v", tview.Escape(field), ttlErr)
+						} else if fieldTTL > 0 {
+							view.log("[green]Field '%s' expires in %s[white]", tview.Escape(field), formatTTL(fieldTTL))
+						} else {
+							view.log("[green]Removed the TTL of field '%s'[white]", tview.Escape(field))
+						}
+					}
+
+					view.closeOverlay()
+					load(nil)
+				}
+			})
+		})
+		form.AddButton("Cancel", view.closeOverlay)
+
+		height := 15
+		if page.TTLs == nil {
+			height = 13
+		}
+		view.showForm(form, 60, height)
+	}
+
+	deleteField := func(field utils.HashField) {
+		question := fmt.Sprintf("Delete field '%s' of '%s'?", field.Field, key)
+		if page.Length == 1 {
+			question += "\n\nIt is the last field, so the key will be deleted too."
+		}
+		view.confirm(question, func() {
+			runner.Start("delete field", func(conn *utils.RedisConnection) func() {
+				err := conn.DeleteHashField(key, field.Field)
+				return func() {
+					if isCancelled(err) {
+						return
+					} else if err != nil {
+						view.log("[red]Error deleting field:[white] %v", err)
+						return
+					}
+					view.log("[green]Deleted field '%s' of '%s'[white]", tview.Escape(field.Field), tview.Escape(key))
+					load(nil)
+				}
+			})
+		})
+	}
+
+	view.table.SetSelectedFunc(func(row, column int) {
+		if field, ttl, ok := selectedField(); ok {
+			showFieldForm(&field, ttl)
+		}
+	})
+
+	// The view opens once the first page is in
+	onKey := func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyDelete {
+			if field, _, ok := selectedField(); ok {
+				deleteField(field)
+			}
+			return nil
+		}
+
+		switch event.Rune() {
+		case 'a':
+			showFieldForm(nil, -1)
+		case 'e':
+			if field, ttl, ok := selectedField(); ok {
+				showFieldForm(&field, ttl)
+			}
+		case 'x':
+			if field, _, ok := selectedField(); ok {
+				deleteField(field)
+			}
+		case 'n':
+			if cursors.hasNext() {
+				cursors.forward()
+				// Stay on this page if the next one cannot be read
+				if !load(func(err error) {
+					if err != nil {
+						cursors.back()
+					}
+				}) {
+					cursors.back()
+				}
+			}
+		case 'p':
+			if cursors.hasPrev() {
+				cursors.back()
+				load(nil)
+			}
+		case 'r':
+			load(nil)
+		case '/':
+			view.prompt("Match fields (glob): ", match, func(text string) {
+				match = text
+				cursors.reset()
+				load(nil)
+			})
+		default:
+			return event
+		}
+		return nil
+	}
+	load(func(err error) {
+		if err != nil {
+			if !isCancelled(err) {
+				view.log("[red]Error reading hash '%s':[white] %v", tview.Escape(key), err)
+			}
+			return
+		}
+		view.open(onKey)
+	})
+}
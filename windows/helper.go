@@ -19,6 +19,12 @@ import (
 
 // Win1.go
 func ExportData(filePath string, redis *utils.RedisConnection) error {
+	return ExportMatching(filePath, redis, "")
+}
+
+// ExportMatching exports the keys matching the glob pattern match, or every
+// key when match is empty, to a CSV file
+func ExportMatching(filePath string, redis *utils.RedisConnection, match string) error {
 	file, err := os.Create(filePath)
 	if err != nil {
 		return fmt.Errorf("error creating CSV file: %v", err)
@@ -32,12 +38,10 @@ func ExportData(filePath string, redis *utils.RedisConnection) error {
 		return fmt.Errorf("error writing CSV header: %v", err)
 	}
 
-	keys, err := redis.GetAllKeys()
-	if err != nil {
-		return fmt.Errorf("error getting keys: %v", err)
-	}
-
-	for _, key := range keys {
+	// Stream keys with SCAN so large keyspaces are never loaded at once
+	it := redis.ScanKeys(redis.Context(), utils.ScanOptions{Match: match})
+	for it.Next() {
+		key := it.Key()
 		value, err := redis.GetValue(key)
 		if err != nil {
 			value = ""
@@ -53,6 +57,9 @@ func ExportData(filePath string, redis *utils.RedisConnection) error {
 			return fmt.Errorf("error writing CSV row: %v", err)
 		}
 	}
+	if err := it.Err(); err != nil {
+		return fmt.Errorf("error scanning keys: %w", err)
+	}
 
 	return nil
 }
@@ -148,6 +155,21 @@ func DisplayHelp(kvDisplay *tview.TextView) {
   • [green]get <key>[-:-:-]
     Retrieve the value of a specified key
     
+  • [green]view [key[][-:-:-]
+    View a key (or the last one fetched with get) as text, a foldable JSON
+    tree, a hex dump, or with gzip/base64/MessagePack decoded
+    
+  • [green]open [key[][-:-:-]
+    Open the editor of a hash (fields with per-field TTLs on Redis 7.4+),
+    list (push, pop, insert, LSET, LREM), set (SSCAN, add, remove) or
+    sorted set (ZADD, ZINCRBY, ZREM, score ranges), or browse a stream
+    (entries, consumer groups, pending entries, XADD, XACK, XCLAIM, XTRIM)
+    
+  • [green]edit <key>[-:-:-]
+    Edit a value in $EDITOR: strings as text, hashes and sorted sets as JSON
+    objects, lists and sets as JSON arrays. The TTL is kept, and nothing is
+    saved if the key changed while you were editing
+    
   • [green]set <key> <value>[-:-:-]
     Set a key with the specified value
     
@@ -163,6 +185,9 @@ func DisplayHelp(kvDisplay *tview.TextView) {
   • [green]expire <key> <seconds>[-:-:-]
     Set a key's time to live in seconds
 
+  • [green]use db [n[][-:-:-]
+    Switch every connection to database n, or pick one with key counts
+
 [::b]Advanced Commands:[-:-:-]
   • [green]key filter set[-:-:-]
     Open form to set a key with TTL in milliseconds
@@ -172,6 +197,13 @@ func DisplayHelp(kvDisplay *tview.TextView) {
     
   • [green]flushall[-:-:-]
     Delete all keys (use with caution)
+    
+  • [green]pubsub[-:-:-]
+    Open the pub/sub console: subscribe on a separate connection, publish,
+    list active channels (PUBSUB CHANNELS/NUMSUB) and save the message log
+    
+  • [green]subscribe / psubscribe <channel ...>[-:-:-]
+    Subscribe in the pub/sub console; unsubscribe / punsubscribe stop
 
 [::b]Data Management:[-:-:-]
   • [green]import[-:-:-]
@@ -184,7 +216,7 @@ func DisplayHelp(kvDisplay *tview.TextView) {
   • [green]help[-:-:-]
     Display this help message
 
-[yellow]Note: Use TAB key to autocomplete commands[-:-:-]`
+[yellow]Note: Use TAB key to autocomplete commands, Esc or Ctrl+C cancels a running command[-:-:-]`
 
 	kvDisplay.SetText(helpText)
 	kvDisplay.SetTextAlign(tview.AlignLeft)
@@ -235,7 +267,7 @@ func ImportForm(app *tview.Application, redis *utils.RedisConnection, kvDisplay
 	return form
 }
 
-func ExportForm(app *tview.Application, redis *utils.RedisConnection, kvDisplay *tview.TextView, logDisplay *tview.TextView, cmdFlex *tview.Flex, formContainer *tview.Flex, suggestionDisplay *tview.TextView, cmdInput *tview.InputField) tview.Primitive {
+func ExportForm(app *tview.Application, redis *utils.RedisConnection, runner *OperationRunner, kvDisplay *tview.TextView, logDisplay *tview.TextView, cmdFlex *tview.Flex, formContainer *tview.Flex, suggestionDisplay *tview.TextView, cmdInput *tview.InputField) tview.Primitive {
 	form := tview.NewForm()
 	form.SetBorder(true).SetTitle(" Export Data ")
 
@@ -249,13 +281,18 @@ func ExportForm(app *tview.Application, redis *utils.RedisConnection, kvDisplay
 		if !strings.HasSuffix(filePath, ".csv") {
 			filePath += ".csv"
 		}
-		err := ExportData(filePath, redis)
-		if err != nil {
-			logDisplay.Write([]byte(fmt.Sprintf("[red]Export Error: %v[white]\n", err)))
-		} else {
-			logDisplay.Write([]byte("[green]Data exported successfully[white]\n"))
-		}
-		RefreshData(logDisplay, kvDisplay, redis)
+		runner.Start("export", func(conn *utils.RedisConnection) func() {
+			err := ExportData(filePath, conn)
+			return func() {
+				if isCancelled(err) {
+					logDisplay.Write([]byte("[yellow]Export cancelled, the file is incomplete[white]\n"))
+				} else if err != nil {
+					logDisplay.Write([]byte(fmt.Sprintf("[red]Export Error: %v[white]\n", err)))
+				} else {
+					logDisplay.Write([]byte("[green]Data exported successfully[white]\n"))
+				}
+			}
+		})
 
 		// Reset the view
 		formContainer.AddItem(form, 0, 1, true)
@@ -283,6 +320,60 @@ func ExportForm(app *tview.Application, redis *utils.RedisConnection, kvDisplay
 	return form
 }
 
+// DatabasePicker lists the logical databases dbs with their key counts and
+// switches the connection to the chosen one
+func DatabasePicker(app *tview.Application, redis *utils.RedisConnection, runner *OperationRunner, dbs []utils.DBInfo, kvDisplay *tview.TextView, logDisplay *tview.TextView, cmdFlex *tview.Flex, formContainer *tview.Flex, suggestionDisplay *tview.TextView, cmdInput *tview.InputField) tview.Primitive {
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetBorder(true).SetTitle(" Select Database [Enter: Use] [Esc: Cancel] ")
+
+	closePicker := func() {
+		cmdFlex.RemoveItem(kvDisplay)
+		cmdFlex.RemoveItem(suggestionDisplay)
+		cmdFlex.RemoveItem(cmdInput)
+		cmdFlex.RemoveItem(formContainer)
+		cmdFlex.AddItem(kvDisplay, 0, 1, false)
+		cmdFlex.AddItem(suggestionDisplay, 3, 0, false)
+		cmdFlex.AddItem(cmdInput, 1, 0, true)
+		app.SetFocus(cmdInput)
+	}
+
+	for _, db := range dbs {
+		db := db
+		label := fmt.Sprintf("db%-3d %8d keys  %8d expiring", db.Index, db.Keys, db.Expires)
+		if db.Index == redis.DB() {
+			label = "[green]" + label + " (current)[white]"
+		}
+		list.AddItem(label, "", 0, func() {
+			started := runner.Start("use db", func(conn *utils.RedisConnection) func() {
+				err := conn.SelectDB(db.Index)
+				return func() {
+					logSelectDB(logDisplay, db.Index, err)
+				}
+			})
+			if started {
+				closePicker()
+			}
+		})
+	}
+	if redis.DB() < list.GetItemCount() {
+		list.SetCurrentItem(redis.DB())
+	}
+
+	list.SetDoneFunc(closePicker)
+	return list
+}
+
+// logSelectDB reports the outcome of switching to database index
+func logSelectDB(logDisplay *tview.TextView, index int, err error) {
+	if isCancelled(err) {
+		return
+	} else if err != nil {
+		logDisplay.Write([]byte(fmt.Sprintf("[red]Error selecting db %d: %v[white]\n", index, err)))
+	} else {
+		logDisplay.Write([]byte(fmt.Sprintf("[green]Now using db %d[white]\n", index)))
+	}
+}
+
 func KeyFilterSetForm(app *tview.Application, redis *utils.RedisConnection, logDisplay *tview.TextView, kvDisplay *tview.TextView, flex *tview.Flex, formContainer *tview.Flex, cmdFlex *tview.Flex, suggestionDisplay *tview.TextView, cmdInput *tview.InputField) tview.Primitive {
 	form := tview.NewForm()
 	form.SetBorder(true).SetTitle(" Key Filter Set ")
@@ -520,7 +611,18 @@ func DisplaySummary(kvDisplay *tview.TextView, stats map[string]interface{}) {
 	sb.WriteString("[yellow]Top Memory Usage Keys:[white]\n")
 	if topKeys, ok := stats["top_memory_keys"].([]utils.KeyMemoryInfo); ok {
 		for _, km := range topKeys {
-			sb.WriteString(fmt.Sprintf("• %s: %d B\n", km.Key, km.Bytes))
+			sb.WriteString(fmt.Sprintf("• %s [gray](%s)[white]: %d B\n", km.Key, km.Type, km.Bytes))
+		}
+	}
+	sb.WriteString("\n")
+
+	// Key Types
+	sb.WriteString("[yellow]Keys by Type:[white]\n")
+	if keysByType, ok := stats["keys_by_type"].(map[string]int64); ok {
+		for _, keyType := range []string{"string", "hash", "list", "set", "zset", "stream"} {
+			if count := keysByType[keyType]; count > 0 {
+				sb.WriteString(fmt.Sprintf("%s: %d\n", keyType, count))
+			}
 		}
 	}
 
@@ -0,0 +1,291 @@
+package windows
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Amrit02102004/RediCLI/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// ListEditor shows a list with the index of every element, a page at a
+// time, with actions to push, pop, insert, set (LSET) and remove (LREM)
+// elements. Redis is read and written through runner, so slow calls can be
+// cancelled.
+func ListEditor(app *tview.Application, runner *OperationRunner, logDisplay *tview.TextView, pane *DisplayPane, key string) {
+	view := newCollectionView(app, pane, logDisplay, "List "+tview.Escape(key),
+		"[gray]a/A push tail/head · i/I insert before/after · Enter/e set · x remove · [/] pop head/tail · n/p page · g go to · Esc close[white]")
+
+	var page *utils.ListPage
+	start := int64(0)
+
+	render := func() {
+		view.table.Clear()
+		view.setColumns("Index", "Value")
+		for i, item := range page.Items {
+			view.table.SetCell(i+1, 0, tview.NewTableCell(strconv.FormatInt(page.Start+int64(i), 10)).
+				SetTextColor(tcell.ColorGray).
+				SetAlign(tview.AlignRight))
+			view.table.SetCell(i+1, 1, tview.NewTableCell(cellText(item)).
+				SetMaxWidth(80).
+				SetExpansion(1))
+		}
+
+		info := fmt.Sprintf("[yellow]%d elements[white]", page.Length)
+		if len(page.Items) > 0 {
+			info += fmt.Sprintf(" · showing %d-%d", page.Start, page.Start+int64(len(page.Items))-1)
+		}
+		if page.Length == 0 {
+			info += " · [red]the key no longer exists[white]"
+		}
+		view.header.SetText(info)
+	}
+
+	// load reads the page at start in the background, selecting row (or
+	// keeping the selection when row is 0), then calls then (when set)
+	// with the outcome
+	load := func(row int, then func(err error)) {
+		from := start
+		runner.Start("list page", func(conn *utils.RedisConnection) func() {
+			next, err := conn.ListRange(key, from)
+			if err == nil && len(next.Items) == 0 && from > 0 && next.Length > 0 {
+				// The list shrank past this page, show its last page instead
+				from = (next.Length - 1) / utils.ListPageSize * utils.ListPageSize
+				next, err = conn.ListRange(key, from)
+			}
+			return func() {
+				if err == nil {
+					if row == 0 {
+						row, _ = view.table.GetSelection()
+					}
+					start, page = from, next
+					render()
+					view.restoreSelection(row)
+					view.footer.SetText(view.hints)
+				} else if !isCancelled(err) {
+					view.showError(err)
+				}
+				if then != nil {
+					then(err)
+				}
+			}
+		})
+	}
+
+	// goTo shows the page holding index and selects it
+	goTo := func(index int64) {
+		start = index / utils.ListPageSize * utils.ListPageSize
+		load(int(index-start)+1, nil)
+	}
+
+	selectedItem := func() (int64, string, bool) {
+		i := view.selected(len(page.Items))
+		if i < 0 {
+			return 0, "", false
+		}
+		return page.Start + int64(i), page.Items[i], true
+	}
+
+	push := func(head bool) {
+		title := "Push to the tail"
+		if head {
+			title = "Push to the head"
+		}
+		view.showValueForm(runner, title, "Value: ", "", func(conn *utils.RedisConnection, value string) func() error {
+			length, err := conn.PushList(key, value, head)
+			return func() error {
+				if err != nil {
+					return err
+				}
+				view.log("[green]%s of '%s', it now has %d elements[white]", title, tview.Escape(key), length)
+				if head {
+					goTo(0)
+				} else {
+					goTo(length - 1)
+				}
+				return nil
+			}
+		})
+	}
+
+	insert := func(before bool) {
+		index, pivot, ok := selectedItem()
+		if !ok {
+			return
+		}
+		where := "after"
+		if before {
+			where = "before"
+		}
+		// LINSERT finds the pivot by value, so duplicates resolve to the first
+		title := fmt.Sprintf("Insert %s element %d", where, index)
+		view.showValueForm(runner, title, "Value: ", "", func(conn *utils.RedisConnection, value string) func() error {
+			length, err := conn.InsertList(key, pivot, value, before)
+			return func() error {
+				if err != nil {
+					return err
+				}
+				view.log("[green]Inserted %s the first element equal to element %d of '%s', it now has %d elements[white]",
+					where, index, tview.Escape(key), length)
+				load(0, nil)
+				return nil
+			}
+		})
+	}
+
+	setItem := func() {
+		index, old, ok := selectedItem()
+		if !ok {
+			return
+		}
+		view.showValueForm(runner, fmt.Sprintf("Set element %d", index), "Value: ", old, func(conn *utils.RedisConnection, value string) func() error {
+			err := conn.SetListItem(key, index, old, value)
+			return func() error {
+				if errors.Is(err, utils.ErrKeyChanged) {
+					load(0, nil)
+					return fmt.Errorf("element %d changed since it was read, the list has been reloaded", index)
+				} else if err != nil {
+					return err
+				}
+				view.log("[green]Set element %d of '%s'[white]", index, tview.Escape(key))
+				load(0, nil)
+				return nil
+			}
+		})
+	}
+
+	removeItems := func() {
+		_, value, ok := selectedItem()
+		if !ok {
+			return
+		}
+		form := tview.NewForm()
+		form.SetBorder(true).SetTitle(fmt.Sprintf(" Remove %s ", cellText(value)))
+		countInput := tview.NewInputField().
+			SetLabel("Count: ").
+			SetText("1").
+			SetFieldWidth(10).
+			SetAcceptanceFunc(tview.InputFieldInteger)
+		form.AddFormItem(countInput)
+		form.AddTextView("", "1 removes the first match, -1 the last and 0 every one", 40, 2, true, false)
+		form.AddButton("Remove", func() {
+			count, err := strconv.ParseInt(countInput.GetText(), 10, 64)
+			if err != nil {
+				view.log("[red]Error:[white] the count must be a whole number")
+				return
+			}
+			runner.Start("remove elements", func(conn *utils.RedisConnection) func() {
+				removed, err := conn.RemoveListItems(key, count, value)
+				return func() {
+					if isCancelled(err) {
+						return
+					} else if err != nil {
+						view.log("[red]Error removing elements:[white] %v", err)
+						return
+					}
+					view.log("[green]Removed %d elements equal to '%s' from '%s'[white]", removed, cellText(value), tview.Escape(key))
+					view.closeOverlay()
+					load(0, nil)
+				}
+			})
+		})
+		form.AddButton("Cancel", view.closeOverlay)
+		view.showForm(form, 60, 10)
+	}
+
+	pop := func(head bool) {
+		end := "tail"
+		if head {
+			end = "head"
+		}
+		view.confirm(fmt.Sprintf("Pop the %s element of '%s'?", end, key), func() {
+			runner.Start("pop", func(conn *utils.RedisConnection) func() {
+				value, err := conn.PopList(key, head)
+				return func() {
+					if isCancelled(err) {
+						return
+					} else if err != nil {
+						view.log("[red]Error popping:[white] %v", err)
+						return
+					}
+					view.log("[green]Popped '%s' from the %s of '%s'[white]", cellText(value), end, tview.Escape(key))
+					load(0, nil)
+				}
+			})
+		})
+	}
+
+	view.table.SetSelectedFunc(func(row, column int) {
+		setItem()
+	})
+
+	// The view opens once the first page is in
+	onKey := func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyDelete {
+			removeItems()
+			return nil
+		}
+
+		switch event.Rune() {
+		case 'a':
+			push(false)
+		case 'A':
+			push(true)
+		case 'i':
+			insert(true)
+		case 'I':
+			insert(false)
+		case 'e':
+			setItem()
+		case 'x':
+			removeItems()
+		case '[':
+			pop(true)
+		case ']':
+			pop(false)
+		case 'n':
+			if start+utils.ListPageSize < page.Length {
+				start += utils.ListPageSize
+				load(1, nil)
+			}
+		case 'p':
+			if start > 0 {
+				start -= utils.ListPageSize
+				load(1, nil)
+			}
+		case 'r':
+			load(0, nil)
+		case 'g':
+			view.prompt("Go to index (negative counts from the tail): ", "", func(text string) {
+				index, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+				if err != nil {
+					view.showError(fmt.Errorf("invalid index '%s'", text))
+					return
+				}
+				if index < 0 {
+					index += page.Length
+				}
+				if index < 0 || index >= page.Length {
+					view.showError(fmt.Errorf("index %s is out of range, the list has %d elements", text, page.Length))
+					return
+				}
+				goTo(index)
+			})
+		default:
+			return event
+		}
+		return nil
+	}
+	load(0, func(err error) {
+		if err != nil {
+			if !isCancelled(err) {
+				view.log("[red]Error reading list '%s':[white] %v", tview.Escape(key), err)
+			}
+			return
+		}
+		view.open(onKey)
+	})
+}
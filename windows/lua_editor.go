@@ -45,8 +45,8 @@ func createRedisModule(redis *utils.RedisConnection) *lua.LTable {
             args[i] = L.ToString(i + 2)
         }
         
-        fullCmd := strings.Join(append([]string{cmd}, args...), " ")
-        result, err := redis.ExecuteCommand(fullCmd)
+        // Arguments are passed through as-is, so values may contain spaces or quotes
+        result, err := redis.ExecuteArgs(append([]string{cmd}, args...))
         
         if err != nil {
             L.Push(lua.LNil)
@@ -0,0 +1,244 @@
+package windows
+
+import (
+	"fmt"
+
+	"github.com/Amrit02102004/RediCLI/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// NamespaceView shows the keyspace as a tree, grouping keys by a delimiter
+// into folders with their key count and memory. Branches are read lazily
+// with SCAN MATCH prefix* when expanded. onKey is called when a key is
+// selected and onToggle when the user asks to go back to the table. Scans,
+// deletes and exports run through runner, so they can be cancelled. The
+// returned function (re)loads the root and must run on the UI goroutine.
+func NamespaceView(app *tview.Application, redis *utils.RedisConnection, runner *OperationRunner, onKey func(key string), onToggle func()) (*tview.Flex, func()) {
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	delimiter := utils.DefaultDelimiter
+
+	root := tview.NewTreeNode("keys").SetColor(tcell.ColorYellow)
+	tree := tview.NewTreeView().SetRoot(root).SetCurrentNode(root)
+	footer := tview.NewTextView().SetDynamicColors(true)
+	prompt := tview.NewInputField()
+
+	help := func() {
+		footer.SetText("[white]t[gray] table  [white]enter[gray] expand  [white]x[gray] delete  [white]e[gray] export  [white]d[gray] delimiter  [white]r[gray] reload[white]")
+	}
+	help()
+
+	nodeText := func(node utils.NamespaceNode) string {
+		if node.Folder {
+			return fmt.Sprintf("%s%s (%d keys, %s)", node.Name, delimiter, node.Keys, formatBytes(node.Memory))
+		}
+		return fmt.Sprintf("%s (%s)", node.Name, formatBytes(node.Memory))
+	}
+
+	// expand reads the level under a folder node and replaces its children
+	var expand func(treeNode *tview.TreeNode)
+	expand = func(treeNode *tview.TreeNode) {
+		folder, _ := treeNode.GetReference().(utils.NamespaceNode)
+		treeNode.ClearChildren()
+		treeNode.AddChild(tview.NewTreeNode("loading...").SetColor(tcell.ColorGray).SetSelectable(false))
+		treeNode.SetExpanded(true)
+
+		delimiter := delimiter
+		started := runner.Start("namespace scan", func(conn *utils.RedisConnection) func() {
+			level, err := conn.ScanNamespace(conn.Context(), folder.Prefix, delimiter)
+			return func() {
+				// Without children the branch is read again on the next Enter
+				treeNode.ClearChildren()
+				if isCancelled(err) {
+					return
+				}
+				if err != nil {
+					treeNode.AddChild(tview.NewTreeNode(fmt.Sprintf("Error: %v", err)).
+						SetColor(tcell.ColorRed).SetSelectable(false))
+					return
+				}
+
+				for _, node := range level.Nodes {
+					child := tview.NewTreeNode(nodeText(node)).SetReference(node)
+					if node.Folder {
+						child.SetColor(tcell.ColorGreen)
+					}
+					treeNode.AddChild(child)
+				}
+				if level.Truncated {
+					treeNode.AddChild(tview.NewTreeNode(fmt.Sprintf("stopped after %d keys, counts are partial", level.Scanned)).
+						SetColor(tcell.ColorGray).SetSelectable(false))
+				}
+				if treeNode == root {
+					root.SetText(fmt.Sprintf("keys in db %d (%d scanned)", redis.DB(), level.Scanned))
+				}
+			}
+		})
+		if !started {
+			treeNode.ClearChildren()
+		}
+	}
+
+	reload := func() {
+		root.SetReference(utils.NamespaceNode{Folder: true})
+		tree.SetCurrentNode(root)
+		expand(root)
+	}
+
+	tree.SetSelectedFunc(func(treeNode *tview.TreeNode) {
+		node, ok := treeNode.GetReference().(utils.NamespaceNode)
+		if !ok {
+			return
+		}
+		if !node.Folder {
+			onKey(node.Key)
+			return
+		}
+		// Children are only read on the first expansion, collapse otherwise
+		if treeNode.IsExpanded() && len(treeNode.GetChildren()) > 0 {
+			treeNode.SetExpanded(false)
+			return
+		}
+		if len(treeNode.GetChildren()) == 0 {
+			expand(treeNode)
+			return
+		}
+		treeNode.SetExpanded(true)
+	})
+
+	// showPrompt replaces the footer with an input, calling done with the
+	// entered text when confirmed with Enter
+	showPrompt := func(label, text string, done func(text string)) {
+		prompt.SetLabel(label).SetText(text)
+		prompt.SetDoneFunc(func(key tcell.Key) {
+			flex.RemoveItem(prompt)
+			flex.AddItem(footer, 1, 0, false)
+			app.SetFocus(tree)
+			help()
+			if key == tcell.KeyEnter {
+				done(prompt.GetText())
+			}
+		})
+		flex.RemoveItem(footer)
+		flex.AddItem(prompt, 1, 0, false)
+		app.SetFocus(prompt)
+	}
+
+	// pendingDelete holds the node awaiting a y/n confirmation
+	var pendingDelete *tview.TreeNode
+	deleteNode := func(treeNode *tview.TreeNode) {
+		node := treeNode.GetReference().(utils.NamespaceNode)
+		started := runner.Start("delete", func(conn *utils.RedisConnection) func() {
+			var deleted int64
+			var err error
+			if node.Folder {
+				deleted, err = conn.DeleteBranch(conn.Context(), node.Prefix)
+			} else {
+				var result interface{}
+				result, err = conn.ExecuteArgs([]string{"del", node.Key})
+				deleted, _ = result.(int64)
+			}
+			return func() {
+				switch {
+				case isCancelled(err):
+					footer.SetText(fmt.Sprintf("[yellow]Delete cancelled after %d keys[white]", deleted))
+				case err != nil:
+					footer.SetText(fmt.Sprintf("[red]Delete failed after %d keys: %v[white]", deleted, err))
+					return
+				default:
+					footer.SetText(fmt.Sprintf("[green]Deleted %d keys[white]", deleted))
+				}
+				reload()
+			}
+		})
+		if !started {
+			help()
+		}
+	}
+
+	tree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if pendingDelete != nil {
+			treeNode := pendingDelete
+			pendingDelete = nil
+			if event.Rune() == 'y' {
+				footer.SetText("[yellow]Deleting...[white]")
+				deleteNode(treeNode)
+			} else {
+				help()
+			}
+			return nil
+		}
+
+		current := tree.GetCurrentNode()
+		node, hasNode := utils.NamespaceNode{}, false
+		if current != nil && current != root {
+			node, hasNode = current.GetReference().(utils.NamespaceNode)
+		}
+
+		switch event.Rune() {
+		case 't':
+			onToggle()
+			return nil
+		case 'r':
+			reload()
+			return nil
+		case 'd':
+			showPrompt("Delimiter: ", delimiter, func(text string) {
+				if text != "" && text != delimiter {
+					delimiter = text
+					reload()
+				}
+			})
+			return nil
+		case 'x':
+			if !hasNode {
+				return nil
+			}
+			pendingDelete = current
+			if node.Folder {
+				count := fmt.Sprintf("%d", node.Keys)
+				if node.Partial {
+					// The scan of its level stopped early
+					count = "at least " + count
+				}
+				footer.SetText(fmt.Sprintf("[red]Delete %s keys under '%s'? (y/n)[white]", count, tview.Escape(node.Prefix)))
+			} else {
+				footer.SetText(fmt.Sprintf("[red]Delete key '%s'? (y/n)[white]", tview.Escape(node.Key)))
+			}
+			return nil
+		case 'e':
+			if !hasNode {
+				return nil
+			}
+			match := utils.GlobEscape(node.Key)
+			if node.Folder {
+				match = utils.GlobEscape(node.Prefix) + "*"
+			}
+			showPrompt("Export to: ", "./export.csv", func(path string) {
+				started := runner.Start("export", func(conn *utils.RedisConnection) func() {
+					err := ExportMatching(path, conn, match)
+					return func() {
+						switch {
+						case isCancelled(err):
+							footer.SetText(fmt.Sprintf("[yellow]Export cancelled, %s is incomplete[white]", tview.Escape(path)))
+						case err != nil:
+							footer.SetText(fmt.Sprintf("[red]Export Error: %v[white]", err))
+						default:
+							footer.SetText(fmt.Sprintf("[green]Exported to %s[white]", tview.Escape(path)))
+						}
+					}
+				})
+				if started {
+					footer.SetText("[yellow]Exporting...[white]")
+				}
+			})
+			return nil
+		}
+		return event
+	})
+
+	flex.AddItem(tree, 0, 1, true)
+	flex.AddItem(footer, 1, 0, false)
+
+	return flex, reload
+}
@@ -0,0 +1,82 @@
+package windows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Amrit02102004/RediCLI/utils"
+	"github.com/rivo/tview"
+)
+
+// OperationRunner runs slow Redis work off the UI goroutine so the TUI stays
+// responsive and the work can be cancelled with Esc or Ctrl+C. Only one
+// operation runs at a time.
+type OperationRunner struct {
+	app        *tview.Application
+	logDisplay *tview.TextView
+	redis      *utils.RedisConnection
+
+	cancel     context.CancelFunc
+	generation int
+}
+
+func NewOperationRunner(app *tview.Application, logDisplay *tview.TextView, redis *utils.RedisConnection) *OperationRunner {
+	return &OperationRunner{
+		app:        app,
+		logDisplay: logDisplay,
+		redis:      redis,
+	}
+}
+
+// Start runs work in the background with a cancellable connection. The
+// function work returns is applied on the UI goroutine once it finishes.
+// Start must be called from the UI goroutine.
+func (o *OperationRunner) Start(name string, work func(conn *utils.RedisConnection) func()) bool {
+	if o.cancel != nil {
+		o.logDisplay.Write([]byte("[yellow]Another operation is still running, press Esc to cancel it[white]\n"))
+		return false
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	o.cancel = cancel
+	o.generation++
+	generation := o.generation
+	o.logDisplay.Write([]byte(fmt.Sprintf("[gray]Running %s... (Esc to cancel)[white]\n", name)))
+
+	conn := o.redis.WithContext(ctx)
+	go func() {
+		done := work(conn)
+		o.app.QueueUpdateDraw(func() {
+			if o.generation == generation && o.cancel != nil {
+				o.cancel()
+				o.cancel = nil
+			}
+			if done != nil {
+				done()
+			}
+		})
+	}()
+	return true
+}
+
+// Running reports whether an operation is in flight.
+func (o *OperationRunner) Running() bool {
+	return o.cancel != nil
+}
+
+// Cancel aborts the operation in flight, reporting whether there was one.
+func (o *OperationRunner) Cancel() bool {
+	if o.cancel == nil {
+		return false
+	}
+	o.cancel()
+	o.cancel = nil
+	o.logDisplay.Write([]byte("[yellow]Operation cancelled[white]\n"))
+	return true
+}
+
+// isCancelled reports whether err comes from a cancelled operation.
+func isCancelled(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,157 @@
+package windows
+
+import (
+	"fmt"
+
+	"github.com/Amrit02102004/RediCLI/utils"
+	"github.com/rivo/tview"
+)
+
+// DisplayPane swaps the key-value display of the command area for a
+// full-size view, such as the value viewer or a collection editor, and
+// puts it back when the view closes. The suggestions and command input
+// stay in place underneath.
+type DisplayPane struct {
+	app               *tview.Application
+	cmdFlex           *tview.Flex
+	formContainer     *tview.Flex
+	kvDisplay         *tview.TextView
+	suggestionDisplay *tview.TextView
+	cmdInput          *tview.InputField
+
+	// returnFocus is where focus goes back to when the view closes
+	returnFocus tview.Primitive
+}
+
+func NewDisplayPane(app *tview.Application, cmdFlex *tview.Flex, formContainer *tview.Flex, kvDisplay *tview.TextView, suggestionDisplay *tview.TextView, cmdInput *tview.InputField) *DisplayPane {
+	return &DisplayPane{
+		app:               app,
+		cmdFlex:           cmdFlex,
+		formContainer:     formContainer,
+		kvDisplay:         kvDisplay,
+		suggestionDisplay: suggestionDisplay,
+		cmdInput:          cmdInput,
+	}
+}
+
+// Show puts view in place of the display and focuses it.
+func (p *DisplayPane) Show(view tview.Primitive) {
+	if p.returnFocus == nil {
+		p.returnFocus = p.app.GetFocus()
+	}
+	p.formContainer.Clear()
+	p.cmdFlex.Clear()
+	p.formContainer.AddItem(view, 0, 1, true)
+	p.cmdFlex.AddItem(p.formContainer, 0, 1, true)
+	p.cmdFlex.AddItem(p.suggestionDisplay, 3, 0, false)
+	p.cmdFlex.AddItem(p.cmdInput, 1, 0, false)
+	p.app.SetFocus(view)
+}
+
+// Close puts the display back and returns focus to where it was before
+// the view was shown.
+func (p *DisplayPane) Close() {
+	p.cmdFlex.Clear()
+	p.formContainer.Clear()
+	p.cmdFlex.AddItem(p.kvDisplay, 0, 1, false)
+	p.cmdFlex.AddItem(p.suggestionDisplay, 3, 0, false)
+	p.cmdFlex.AddItem(p.cmdInput, 1, 0, true)
+
+	focus := p.returnFocus
+	p.returnFocus = nil
+	if focus == nil {
+		focus = p.cmdInput
+	}
+	p.app.SetFocus(focus)
+}
+
+// KeyViews opens the full-size views of a key, from the command line or
+// the key table. The key is read through the operation runner, so a slow
+// server can be cancelled with Esc or Ctrl+C.
+type KeyViews struct {
+	app        *tview.Application
+	redis      *utils.RedisConnection
+	logDisplay *tview.TextView
+	pane       *DisplayPane
+	viewer     *ValueViewer
+	runner     *OperationRunner
+}
+
+func NewKeyViews(app *tview.Application, redis *utils.RedisConnection, logDisplay *tview.TextView, pane *DisplayPane, runner *OperationRunner) *KeyViews {
+	return &KeyViews{
+		app:        app,
+		redis:      redis,
+		logDisplay: logDisplay,
+		pane:       pane,
+		viewer:     NewValueViewer(app, redis, pane),
+		runner:     runner,
+	}
+}
+
+// View opens key in the value viewer.
+func (v *KeyViews) View(key string) {
+	v.runner.Start("view", func(conn *utils.RedisConnection) func() {
+		value, err := conn.GetTypedValue(key)
+		return func() {
+			if isCancelled(err) {
+				return
+			}
+			if err != nil {
+				v.pane.kvDisplay.SetText(fmt.Sprintf("[red]Error retrieving '%s':[white] %v", tview.Escape(key), err))
+				return
+			}
+			v.viewer.Show(key, value)
+		}
+	})
+}
+
+// Open opens the editor for key's type, falling back to the value viewer
+// for types without one.
+func (v *KeyViews) Open(key string) {
+	v.runner.Start("open", func(conn *utils.RedisConnection) func() {
+		kind, err := conn.GetKeyType(key)
+		// The value viewer needs the value too
+		var value *utils.Value
+		if err == nil && !hasEditor(kind) && kind != "none" {
+			value, err = conn.GetTypedValue(key)
+		}
+		return func() {
+			if isCancelled(err) {
+				return
+			}
+			if err != nil {
+				v.logDisplay.Write([]byte(fmt.Sprintf("[red]Error:[white] %v\n", err)))
+				return
+			}
+			v.open(key, kind, value)
+		}
+	})
+}
+
+func hasEditor(kind string) bool {
+	switch kind {
+	case "hash", "list", "set", "zset", "stream":
+		return true
+	}
+	return false
+}
+
+func (v *KeyViews) open(key string, kind string, value *utils.Value) {
+	switch kind {
+	case "hash":
+		HashEditor(v.app, v.runner, v.logDisplay, v.pane, key)
+	case "list":
+		ListEditor(v.app, v.runner, v.logDisplay, v.pane, key)
+	case "set":
+		SetEditor(v.app, v.runner, v.logDisplay, v.pane, key)
+	case "zset":
+		ZSetEditor(v.app, v.runner, v.logDisplay, v.pane, key)
+	case "stream":
+		StreamBrowser(v.app, v.runner, v.logDisplay, v.pane, key)
+	case "none":
+		v.logDisplay.Write([]byte(fmt.Sprintf("[yellow]Key '%s' does not exist[white]\n", tview.Escape(key))))
+	default:
+		v.logDisplay.Write([]byte(fmt.Sprintf("[yellow]There is no editor for %s values, showing the value viewer[white]\n", kind)))
+		v.viewer.Show(key, value)
+	}
+}
@@ -0,0 +1,489 @@
+package windows
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Amrit02102004/RediCLI/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// maxPubSubMessages is how many received messages the console keeps.
+const maxPubSubMessages = 10000
+
+// channelColours tell channels apart in the message log
+var channelColours = []string{"green", "yellow", "aqua", "fuchsia", "orange", "lime", "skyblue", "violet", "gold", "tomato"}
+
+// channelColour picks the same colour for a channel every time
+func channelColour(channel string) string {
+	h := fnv.New32a()
+	h.Write([]byte(channel))
+	return channelColours[h.Sum32()%uint32(len(channelColours))]
+}
+
+// PubSubConsole subscribes to channels and patterns on a connection of its
+// own and logs the messages received, with a box to publish messages and
+// an overview of the server's active channels. It keeps listening while it
+// is closed, so messages are not missed when it is opened again, and across
+// database switches and reconnects, until it is told to unsubscribe.
+type PubSubConsole struct {
+	app        *tview.Application
+	runner     *OperationRunner
+	logDisplay *tview.TextView
+
+	view         *collectionView
+	messages     *tview.TextView
+	channelInput *tview.InputField
+	payloadInput *tview.InputField
+	filter       string // pattern of the active channels overview
+
+	sub *utils.Subscription
+
+	// received is only touched on the UI goroutine; pending is filled by the
+	// receiving goroutine and drained there
+	received []utils.PubSubMessage
+	total    int
+	mu       sync.Mutex
+	pending  []utils.PubSubMessage
+	queued   bool
+}
+
+func NewPubSubConsole(app *tview.Application, runner *OperationRunner, logDisplay *tview.TextView, pane *DisplayPane) *PubSubConsole {
+	c := &PubSubConsole{
+		app:        app,
+		runner:     runner,
+		logDisplay: logDisplay,
+		view: newCollectionView(app, pane, logDisplay, "Pub/Sub",
+			"[gray]s subscribe · p psubscribe · u unsubscribe · m publish · / filter channels · o refresh · w save · c clear · Tab focus · Esc close[white]"),
+		messages: tview.NewTextView().
+			SetDynamicColors(true).
+			SetMaxLines(maxPubSubMessages),
+		channelInput: tview.NewInputField().SetLabel("Publish to: ").SetFieldWidth(24),
+		payloadInput: tview.NewInputField().SetLabel(" Message: "),
+	}
+	c.messages.SetBorder(true).SetTitle(" Messages ")
+	c.messages.ScrollToEnd()
+	c.view.table.SetBorder(true).SetTitle(" Active channels ")
+
+	// The message log takes the place of the table, which becomes a side
+	// panel, and the publish box sits above the footer
+	publish := tview.NewFlex().
+		AddItem(c.channelInput, 36, 0, false).
+		AddItem(c.payloadInput, 0, 1, false)
+	c.view.body.Clear().
+		AddItem(c.view.header, 1, 0, false).
+		AddItem(tview.NewFlex().
+			AddItem(c.messages, 0, 3, true).
+			AddItem(c.view.table, 0, 1, false), 0, 1, true).
+		AddItem(publish, 1, 0, false).
+		AddItem(c.view.footer, 1, 0, false)
+
+	c.messages.SetInputCapture(c.onKey)
+	c.view.table.SetSelectedFunc(func(row, column int) {
+		if cell := c.view.table.GetCell(row, 0); cell.Reference != nil {
+			c.channelInput.SetText(cell.Reference.(string))
+			c.app.SetFocus(c.payloadInput)
+		}
+	})
+	c.channelInput.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter, tcell.KeyTab:
+			c.app.SetFocus(c.payloadInput)
+		case tcell.KeyBacktab:
+			c.app.SetFocus(c.view.table)
+		case tcell.KeyEscape:
+			c.app.SetFocus(c.messages)
+		}
+	})
+	c.payloadInput.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			c.publish()
+		case tcell.KeyTab:
+			c.app.SetFocus(c.messages)
+		case tcell.KeyBacktab:
+			c.app.SetFocus(c.channelInput)
+		case tcell.KeyEscape:
+			c.app.SetFocus(c.messages)
+		}
+	})
+	return c
+}
+
+// Show opens the console, refreshing the active channels.
+func (c *PubSubConsole) Show() {
+	c.show()
+	c.refresh()
+}
+
+// show opens the console as it was left
+func (c *PubSubConsole) show() {
+	c.render()
+	c.view.open(c.onKey)
+	c.app.SetFocus(c.messages)
+}
+
+// Subscribe subscribes to channels, or to patterns with PSUBSCRIBE, and
+// opens the console.
+func (c *PubSubConsole) Subscribe(names []string, patterns bool) {
+	c.show()
+	if err := c.subscribe(names, patterns); err != nil {
+		c.view.showError(err)
+	}
+}
+
+// Unsubscribe stops listening to channels, or to patterns, and to all of
+// them when names is empty.
+func (c *PubSubConsole) Unsubscribe(names []string, patterns bool) {
+	if patterns {
+		c.unsubscribe(nil, names, false, true)
+	} else {
+		c.unsubscribe(names, nil, true, false)
+	}
+}
+
+// unsubscribe stops listening to channels and patterns in one operation;
+// fromChannels and fromPatterns with no names mean all of them
+func (c *PubSubConsole) unsubscribe(channels, patterns []string, fromChannels, fromPatterns bool) {
+	sub := c.sub
+	if sub == nil {
+		c.view.log("[yellow]Not subscribed to anything[white]")
+		return
+	}
+
+	c.runner.Start("unsubscribe", func(conn *utils.RedisConnection) func() {
+		var err error
+		if fromChannels {
+			err = sub.Unsubscribe(conn.Context(), channels...)
+		}
+		if err == nil && fromPatterns {
+			err = sub.PUnsubscribe(conn.Context(), patterns...)
+		}
+		return func() {
+			if isCancelled(err) {
+				return
+			} else if err != nil {
+				c.view.log("[red]Error unsubscribing:[white] %v", err)
+				c.render()
+				return
+			}
+			var what []string
+			switch {
+			case !fromChannels:
+			case len(channels) == 0:
+				what = append(what, "all channels")
+			default:
+				what = append(what, tview.Escape(strings.Join(channels, ", ")))
+			}
+			switch {
+			case !fromPatterns:
+			case len(patterns) == 0:
+				what = append(what, "all patterns")
+			default:
+				what = append(what, tview.Escape(strings.Join(patterns, ", ")))
+			}
+			c.view.log("[green]Unsubscribed from %s[white]", strings.Join(what, " and "))
+			c.render()
+		}
+	})
+}
+
+// subscribe starts listening to names, opening the subscription on its own
+// connection the first time
+func (c *PubSubConsole) subscribe(names []string, patterns bool) error {
+	if len(names) == 0 {
+		return fmt.Errorf("name at least one channel or pattern")
+	}
+
+	existing := c.sub
+	c.runner.Start("subscribe", func(conn *utils.RedisConnection) func() {
+		sub := existing
+		var err error
+		if sub == nil {
+			if sub, err = conn.NewSubscription(); err != nil {
+				return func() {
+					if !isCancelled(err) {
+						c.view.showError(err)
+					}
+				}
+			}
+		}
+
+		if patterns {
+			err = sub.PSubscribe(conn.Context(), names...)
+		} else {
+			err = sub.Subscribe(conn.Context(), names...)
+		}
+		if err != nil && existing == nil {
+			sub.Close()
+			sub = nil
+		}
+		return func() {
+			if sub != nil && existing == nil {
+				c.sub = sub
+				go c.receive(sub)
+			}
+			if isCancelled(err) {
+				return
+			} else if err != nil {
+				c.view.showError(err)
+				return
+			}
+			kind := "channels"
+			if patterns {
+				kind = "patterns"
+			}
+			c.view.log("[green]Subscribed to %s %s[white]", kind, tview.Escape(strings.Join(names, ", ")))
+			c.render()
+			c.refresh()
+		}
+	})
+	return nil
+}
+
+// receive queues the messages of sub for the UI goroutine, drawing at most
+// once per batch so busy channels do not flood the event queue
+func (c *PubSubConsole) receive(sub *utils.Subscription) {
+	for msg := range sub.Messages() {
+		c.mu.Lock()
+		c.pending = append(c.pending, msg)
+		queued := c.queued
+		c.queued = true
+		c.mu.Unlock()
+		if !queued {
+			c.app.QueueUpdateDraw(c.flush)
+		}
+	}
+
+	// The subscription was closed
+	c.app.QueueUpdateDraw(func() {
+		if c.sub == sub {
+			c.sub = nil
+			c.view.log("[yellow]The pub/sub connection closed, subscriptions ended[white]")
+			c.render()
+		}
+	})
+}
+
+// flush writes the queued messages to the log
+func (c *PubSubConsole) flush() {
+	c.mu.Lock()
+	batch := c.pending
+	c.pending, c.queued = nil, false
+	c.mu.Unlock()
+
+	var lines strings.Builder
+	for _, msg := range batch {
+		colour := channelColour(msg.Channel)
+		fmt.Fprintf(&lines, "[gray]%s[-] [%s]%s[-]", msg.Time.Format("15:04:05.000"), colour, tview.Escape(msg.Channel))
+		if msg.Pattern != "" {
+			fmt.Fprintf(&lines, " [gray](%s)[-]", tview.Escape(msg.Pattern))
+		}
+		fmt.Fprintf(&lines, " %s\n", cellText(msg.Payload))
+	}
+	c.messages.Write([]byte(lines.String()))
+
+	c.total += len(batch)
+	c.received = append(c.received, batch...)
+	if over := len(c.received) - maxPubSubMessages; over > 0 {
+		c.received = append(c.received[:0:0], c.received[over:]...)
+	}
+	c.render()
+}
+
+// render updates the header with the subscriptions and message counts
+func (c *PubSubConsole) render() {
+	if c.sub == nil {
+		c.view.header.SetText(fmt.Sprintf("[gray]Not subscribed · %d messages received[white]", c.total))
+		return
+	}
+
+	var parts []string
+	if channels := c.sub.Channels(); len(channels) > 0 {
+		parts = append(parts, "channels "+tview.Escape(strings.Join(channels, ", ")))
+	}
+	if patterns := c.sub.Patterns(); len(patterns) > 0 {
+		parts = append(parts, "patterns "+tview.Escape(strings.Join(patterns, ", ")))
+	}
+	subscribed := "[gray]Not subscribed[white]"
+	if len(parts) > 0 {
+		subscribed = "Subscribed to [green]" + strings.Join(parts, "[white] and [green]") + "[white]"
+	}
+	c.view.header.SetText(fmt.Sprintf("%s · [yellow]%d messages[white] received", subscribed, c.total))
+}
+
+// refresh lists the active channels with PUBSUB CHANNELS and NUMSUB
+func (c *PubSubConsole) refresh() {
+	filter := c.filter
+	c.runner.Start("active channels", func(conn *utils.RedisConnection) func() {
+		channels, patterns, err := conn.ActiveChannels(filter)
+		return func() {
+			if isCancelled(err) {
+				return
+			} else if err != nil {
+				c.view.showError(err)
+				return
+			}
+			c.showChannels(channels, patterns, filter)
+		}
+	})
+}
+
+// showChannels fills the side panel with the active channels
+func (c *PubSubConsole) showChannels(channels []utils.ChannelInfo, patterns int64, filter string) {
+	table := c.view.table
+	row, _ := table.GetSelection()
+	table.Clear()
+	c.view.setColumns("Channel", "Subs")
+	for i, ch := range channels {
+		table.SetCell(i+1, 0, tview.NewTableCell(cellText(ch.Name)).
+			SetTextColor(tcell.GetColor(channelColour(ch.Name))).
+			SetReference(ch.Name).
+			SetExpansion(1))
+		table.SetCell(i+1, 1, tview.NewTableCell(strconv.FormatInt(ch.Subscribers, 10)).
+			SetAlign(tview.AlignRight))
+	}
+	if len(channels) == 0 {
+		table.SetCell(1, 0, tview.NewTableCell("No active channels").
+			SetTextColor(tcell.ColorGray).
+			SetSelectable(false))
+	}
+	title := fmt.Sprintf(" Active channels · %d patterns ", patterns)
+	if filter != "" {
+		title = fmt.Sprintf(" Channels matching %s · %d patterns ", tview.Escape(filter), patterns)
+	}
+	table.SetTitle(title)
+	c.view.restoreSelection(row)
+	c.view.footer.SetText(c.view.hints)
+}
+
+func (c *PubSubConsole) publish() {
+	channel := c.channelInput.GetText()
+	if channel == "" {
+		c.view.showError(fmt.Errorf("type the channel to publish to"))
+		c.app.SetFocus(c.channelInput)
+		return
+	}
+
+	payload := c.payloadInput.GetText()
+	c.runner.Start("publish", func(conn *utils.RedisConnection) func() {
+		receivers, err := conn.Publish(channel, payload)
+		return func() {
+			if isCancelled(err) {
+				return
+			} else if err != nil {
+				c.view.showError(err)
+				return
+			}
+			if c.payloadInput.GetText() == payload {
+				c.payloadInput.SetText("")
+			}
+			c.view.footer.SetText(fmt.Sprintf("[green]Published to %s, %d clients received it[white]", tview.Escape(channel), receivers))
+		}
+	})
+}
+
+// save writes the messages kept so far to path
+func (c *PubSubConsole) save(path string) {
+	file, err := os.Create(path)
+	if err != nil {
+		c.view.showError(err)
+		return
+	}
+	err = utils.WritePubSubLog(file, c.received)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		c.view.showError(err)
+		return
+	}
+	c.view.log("[green]Saved %d messages to %s[white]", len(c.received), tview.Escape(path))
+	c.view.footer.SetText(fmt.Sprintf("[green]Saved %d messages to %s[white]", len(c.received), tview.Escape(path)))
+}
+
+// onKey handles the keys of the message log and the channel list
+func (c *PubSubConsole) onKey(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEscape:
+		c.view.pane.Close()
+		return nil
+	case tcell.KeyTab:
+		if c.app.GetFocus() == c.messages {
+			c.app.SetFocus(c.view.table)
+		} else {
+			c.app.SetFocus(c.channelInput)
+		}
+		return nil
+	case tcell.KeyBacktab:
+		if c.app.GetFocus() == c.messages {
+			c.app.SetFocus(c.payloadInput)
+		} else {
+			c.app.SetFocus(c.messages)
+		}
+		return nil
+	}
+
+	switch event.Rune() {
+	case 's', 'p':
+		patterns := event.Rune() == 'p'
+		label := "Subscribe to channels: "
+		if patterns {
+			label = "Subscribe to patterns (glob): "
+		}
+		c.view.prompt(label, "", func(text string) {
+			names, err := utils.SplitArgs(text)
+			if err == nil {
+				err = c.subscribe(names, patterns)
+			}
+			if err != nil {
+				c.view.showError(err)
+			}
+		})
+	case 'u':
+		c.view.prompt("Unsubscribe from (empty for all, p:<pattern> for patterns): ", "", func(text string) {
+			names, err := utils.SplitArgs(text)
+			if err != nil {
+				c.view.showError(err)
+				return
+			}
+			var channels, patterns []string
+			for _, name := range names {
+				if pattern, ok := strings.CutPrefix(name, "p:"); ok {
+					patterns = append(patterns, pattern)
+				} else {
+					channels = append(channels, name)
+				}
+			}
+			c.unsubscribe(channels, patterns, len(names) == 0 || len(channels) > 0, len(names) == 0 || len(patterns) > 0)
+		})
+	case 'm':
+		c.app.SetFocus(c.channelInput)
+	case '/':
+		c.view.prompt("Active channels matching (glob): ", c.filter, func(text string) {
+			c.filter = strings.TrimSpace(text)
+			c.refresh()
+		})
+	case 'o':
+		c.refresh()
+	case 'w':
+		name := "redicli-pubsub-" + time.Now().Format("20060102-150405") + ".log"
+		c.view.prompt("Save the log to: ", name, func(text string) {
+			if text = strings.TrimSpace(text); text != "" {
+				c.save(text)
+			}
+		})
+	case 'c':
+		c.messages.Clear()
+		c.received = nil
+	default:
+		return event
+	}
+	return nil
+}
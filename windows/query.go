@@ -15,6 +15,7 @@ type QueryCondition struct {
 	TTLValue       int    // milliseconds
 	ValuePattern   string // SQL LIKE pattern or regex pattern
 	KeyPattern     string // SQL LIKE pattern or regex pattern
+	KeyGlob        string // SCAN MATCH pattern derived from a LIKE key pattern
 	IsRegexValue   bool   // true if value pattern is regex
 	IsRegexKey     bool   // true if key pattern is regex
 	ConnectionName string
@@ -125,6 +126,7 @@ func parseKeyCondition(condition string, qc *QueryCondition) error {
 	if strings.Contains(condition, "like") {
 		pattern := extractPattern(condition, "like")
 		qc.KeyPattern = sqlLikeToRegex(pattern)
+		qc.KeyGlob = sqlLikeToGlob(pattern)
 		qc.IsRegexKey = false
 	} else if strings.Contains(condition, "regex") {
 		pattern := extractPattern(condition, "regex")
@@ -156,20 +158,34 @@ func sqlLikeToRegex(pattern string) string {
 	return "^" + pattern + "$"
 }
 
+// sqlLikeToGlob converts a SQL LIKE pattern into a Redis glob for SCAN MATCH
+func sqlLikeToGlob(pattern string) string {
+	var glob strings.Builder
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			glob.WriteRune('*')
+		case '_':
+			glob.WriteRune('?')
+		default:
+			glob.WriteString(utils.GlobEscape(string(r)))
+		}
+	}
+	return glob.String()
+}
+
 // ExecuteQuery executes the query and returns matching keys and their values
 func ExecuteQuery(redis *utils.RedisConnection, condition *QueryCondition) (map[string]string, error) {
 	if !redis.IsConnected() {
 		return nil, fmt.Errorf("not connected to Redis")
 	}
 
-	// Get all keys
-	keys, err := redis.GetAllKeys()
-	if err != nil {
-		return nil, err
-	}
+	// Scan keys incrementally, letting the server pre-filter LIKE key patterns
+	it := redis.ScanKeys(redis.Context(), utils.ScanOptions{Match: condition.KeyGlob})
 
 	results := make(map[string]string)
-	for _, key := range keys {
+	for it.Next() {
+		key := it.Key()
 		// Check key pattern if specified
 		if condition.KeyPattern != "" {
 			matched, err := regexp.MatchString(condition.KeyPattern, key)
@@ -224,6 +240,9 @@ func ExecuteQuery(redis *utils.RedisConnection, condition *QueryCondition) (map[
 			results[key] = value
 		}
 	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
 
 	return results, nil
 }
@@ -329,6 +348,9 @@ func ExecuteUpdateQuery(redis *utils.RedisConnection, query *UpdateQuery) (int,
 
 	// Process each matching key
 	for key := range matches {
+		if err := redis.Context().Err(); err != nil {
+			return updatedCount, err
+		}
 		switch query.UpdateType {
 		case UpdateValue:
 			err = updateValue(redis, key, query.NewValue)
@@ -359,27 +381,8 @@ func updateValue(redis *utils.RedisConnection, key, newValue string) error {
 }
 
 func updateKey(redis *utils.RedisConnection, oldKey, newKey string) error {
-	// Get current value
-	value, err := redis.GetValue(oldKey)
-	if err != nil {
-		return err
-	}
-
-	// Get current TTL
-	ttl, err := redis.GetTTL(oldKey)
-	if err != nil {
-		return err
-	}
-
-	// Set new key with same value and TTL
-	err = redis.SetKeyWithTTL(newKey, value, ttl)
-	if err != nil {
-		return err
-	}
-
-	// Delete old key
-	_, err = redis.ExecuteCommand(fmt.Sprintf("del %s", oldKey))
-	return err
+	// RENAME keeps the value, its type and the TTL
+	return redis.Rename(oldKey, newKey)
 }
 
 func updateTTL(redis *utils.RedisConnection, key, ttlStr string) error {
@@ -392,14 +395,8 @@ func updateTTL(redis *utils.RedisConnection, key, ttlStr string) error {
 	// Convert to duration
 	ttl := time.Duration(ttlMs) * time.Millisecond
 
-	// Get current value
-	value, err := redis.GetValue(key)
-	if err != nil {
-		return err
-	}
-
-	// Update TTL
-	return redis.SetKeyWithTTL(key, value, ttl)
+	// Update TTL without rewriting the value, so non-string keys keep their type
+	return redis.Expire(key, ttl)
 }
 
 
@@ -442,7 +439,9 @@ func ParseDeleteQuery(query string) (*DeleteQuery, error) {
 }
 
 // ExecuteDeleteQuery executes the delete query and returns a confirmation function along with matched keys
-func ExecuteDeleteQuery(redis *utils.RedisConnection, deleteQuery *DeleteQuery) (confirmFunc func() (int, error), matchedKeys []string, err error) {
+// The confirmation function takes the connection to delete with, so the
+// deletion can run as its own cancellable operation.
+func ExecuteDeleteQuery(redis *utils.RedisConnection, deleteQuery *DeleteQuery) (confirmFunc func(conn *utils.RedisConnection) (int, error), matchedKeys []string, err error) {
     if !redis.IsConnected() {
         return nil, nil, fmt.Errorf("not connected to Redis")
     }
@@ -460,10 +459,13 @@ func ExecuteDeleteQuery(redis *utils.RedisConnection, deleteQuery *DeleteQuery)
     }
 
     // Return confirmation function
-    confirmFunc = func() (int, error) {
+    confirmFunc = func(conn *utils.RedisConnection) (int, error) {
         deletedCount := 0
         for _, key := range matchedKeys {
-            _, err := redis.ExecuteCommand(fmt.Sprintf("del %s", key))
+            if err := conn.Context().Err(); err != nil {
+                return deletedCount, err
+            }
+            _, err := conn.ExecuteArgs([]string{"del", key})
             if err != nil {
                 continue
             }
@@ -0,0 +1,202 @@
+package windows
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Amrit02102004/RediCLI/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// SetEditor lists the members of a set, an SSCAN page at a time, with
+// actions to add, replace and remove members. Redis is read and written
+// through runner, so slow calls can be cancelled.
+func SetEditor(app *tview.Application, runner *OperationRunner, logDisplay *tview.TextView, pane *DisplayPane, key string) {
+	view := newCollectionView(app, pane, logDisplay, "Set "+tview.Escape(key),
+		"[gray]a add · Enter/e replace · x remove · n/p page · / match · r reload · Esc close[white]")
+
+	var page *utils.SetPage
+	cursors := newCursorPages()
+	match := ""
+
+	render := func() {
+		view.table.Clear()
+		view.setColumns("Member")
+		for i, member := range page.Members {
+			view.table.SetCell(i+1, 0, tview.NewTableCell(cellText(member)).
+				SetMaxWidth(100).
+				SetExpansion(1))
+		}
+
+		info := fmt.Sprintf("[yellow]%d members[white] · page %d", page.Length, cursors.number())
+		if cursors.hasNext() {
+			info += " (n for more)"
+		}
+		if match != "" {
+			info += fmt.Sprintf(" · matching [green]%s[white]", tview.Escape(match))
+		}
+		if page.Length == 0 {
+			info += " · [red]the key no longer exists[white]"
+		}
+		view.header.SetText(info)
+	}
+
+	// load reads the page at the current cursor in the background, keeping
+	// the selected row, then calls then (when set) with the outcome. It
+	// reports whether the read could be started.
+	load := func(then func(err error)) bool {
+		cursor, match := cursors.current(), match
+		return runner.Start("set page", func(conn *utils.RedisConnection) func() {
+			next, err := conn.ScanSet(key, cursor, match)
+			return func() {
+				if err == nil {
+					row, _ := view.table.GetSelection()
+					page = next
+					cursors.next = page.Next
+					render()
+					view.restoreSelection(row)
+					view.footer.SetText(view.hints)
+				} else if !isCancelled(err) {
+					view.showError(err)
+				}
+				if then != nil {
+					then(err)
+				}
+			}
+		})
+	}
+
+	selectedMember := func() (string, bool) {
+		i := view.selected(len(page.Members))
+		if i < 0 {
+			return "", false
+		}
+		return page.Members[i], true
+	}
+
+	addMember := func() {
+		view.showValueForm(runner, "Add member", "Member: ", "", func(conn *utils.RedisConnection, member string) func() error {
+			added, err := conn.AddSetMember(key, member)
+			return func() error {
+				if err != nil {
+					return err
+				}
+				if !added {
+					return fmt.Errorf("'%s' is already a member", member)
+				}
+				view.log("[green]Added '%s' to '%s'[white]", cellText(member), tview.Escape(key))
+				load(nil)
+				return nil
+			}
+		})
+	}
+
+	replaceMember := func() {
+		member, ok := selectedMember()
+		if !ok {
+			return
+		}
+		view.showValueForm(runner, "Replace member", "Member: ", member, func(conn *utils.RedisConnection, replacement string) func() error {
+			if replacement == member {
+				return func() error { return nil }
+			}
+			err := conn.ReplaceSetMember(key, member, replacement)
+			return func() error {
+				if errors.Is(err, utils.ErrKeyChanged) {
+					load(nil)
+					return fmt.Errorf("the set changed since '%s' was read, it has been reloaded", member)
+				} else if err != nil {
+					return err
+				}
+				view.log("[green]Replaced '%s' with '%s' in '%s'[white]", cellText(member), cellText(replacement), tview.Escape(key))
+				load(nil)
+				return nil
+			}
+		})
+	}
+
+	removeMember := func() {
+		member, ok := selectedMember()
+		if !ok {
+			return
+		}
+		question := fmt.Sprintf("Remove '%s' from '%s'?", member, key)
+		if page.Length == 1 {
+			question += "\n\nIt is the last member, so the key will be deleted too."
+		}
+		view.confirm(question, func() {
+			runner.Start("remove member", func(conn *utils.RedisConnection) func() {
+				err := conn.RemoveSetMember(key, member)
+				return func() {
+					if isCancelled(err) {
+						return
+					} else if err != nil {
+						view.log("[red]Error removing member:[white] %v", err)
+						return
+					}
+					view.log("[green]Removed '%s' from '%s'[white]", cellText(member), tview.Escape(key))
+					load(nil)
+				}
+			})
+		})
+	}
+
+	view.table.SetSelectedFunc(func(row, column int) {
+		replaceMember()
+	})
+
+	// The view opens once the first page is in
+	onKey := func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyDelete {
+			removeMember()
+			return nil
+		}
+
+		switch event.Rune() {
+		case 'a':
+			addMember()
+		case 'e':
+			replaceMember()
+		case 'x':
+			removeMember()
+		case 'n':
+			if cursors.hasNext() {
+				cursors.forward()
+				// Stay on this page if the next one cannot be read
+				if !load(func(err error) {
+					if err != nil {
+						cursors.back()
+					}
+				}) {
+					cursors.back()
+				}
+			}
+		case 'p':
+			if cursors.hasPrev() {
+				cursors.back()
+				load(nil)
+			}
+		case 'r':
+			load(nil)
+		case '/':
+			view.prompt("Match members (glob): ", match, func(text string) {
+				match = text
+				cursors.reset()
+				load(nil)
+			})
+		default:
+			return event
+		}
+		return nil
+	}
+	load(func(err error) {
+		if err != nil {
+			if !isCancelled(err) {
+				view.log("[red]Error reading set '%s':[white] %v", tview.Escape(key), err)
+			}
+			return
+		}
+		view.open(onKey)
+	})
+}
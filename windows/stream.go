@@ -0,0 +1,578 @@
+package windows
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Amrit02102004/RediCLI/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Stream browser tabs, in the order of their number keys
+const (
+	streamEntries = iota
+	streamGroups
+	streamConsumers
+	streamPending
+)
+
+var streamTabs = []string{"Entries", "Groups", "Consumers", "Pending"}
+
+var streamHints = []string{
+	"[gray]n/p page · o order · g go to ID · Enter details · a XADD · t XTRIM · r reload · 1-4/Tab tabs · Esc close[white]",
+	"[gray]Enter pending entries of the group · a XADD · t XTRIM · r reload · 1-4/Tab tabs · Esc close[white]",
+	"[gray]Enter pending entries of the consumer · r reload · 1-4/Tab tabs · Esc close[white]",
+	"[gray]k XACK · c XCLAIM · f all consumers · Enter details · r reload · 1-4/Tab tabs · Esc close[white]",
+}
+
+// entryFields renders the fields of an entry sorted by name, joined by sep
+func entryFields(entry utils.StreamEntry, sep string, colour bool) string {
+	names := make([]string, 0, len(entry.Fields))
+	for name := range entry.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		value := cellText(fmt.Sprint(entry.Fields[name]))
+		if colour {
+			parts[i] = fmt.Sprintf("[green]%s[white]: %s", cellText(name), value)
+		} else {
+			parts[i] = fmt.Sprintf("%s=%s", cellText(name), value)
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+// entryTime renders the creation time encoded in an entry ID
+func entryTime(id string) string {
+	if t, ok := utils.StreamIDTime(id); ok {
+		return t.Format("2006-01-02 15:04:05.000")
+	}
+	return ""
+}
+
+// parseEntryFields reads field=value lines typed for XADD
+func parseEntryFields(text string) ([]string, error) {
+	var fields []string
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("line %d: use field=value", i+1)
+		}
+		fields = append(fields, name, value)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("an entry needs at least one field=value line")
+	}
+	return fields, nil
+}
+
+// StreamBrowser shows a stream in tabs: its entries paged with XRANGE or
+// XREVRANGE, its consumer groups (XINFO GROUPS), the consumers of a group
+// (XINFO CONSUMERS) and the group's pending entries with their idle times
+// (XPENDING). Entries can be added (XADD) and the stream trimmed (XTRIM);
+// pending entries can be acknowledged (XACK) or claimed (XCLAIM). Redis is
+// read and written through runner, so slow calls can be cancelled.
+func StreamBrowser(app *tview.Application, runner *OperationRunner, logDisplay *tview.TextView, pane *DisplayPane, key string) {
+	view := newCollectionView(app, pane, logDisplay, "Stream "+tview.Escape(key), streamHints[streamEntries])
+	view.body.ResizeItem(view.header, 2, 0)
+
+	mode := streamEntries
+	var info *utils.StreamInfo
+
+	// Entries tab
+	var page *utils.StreamPage
+	starts := []string{""}
+	reverse := false
+
+	// Groups, consumers and pending tabs
+	var groups []utils.StreamGroup
+	var consumers []utils.StreamConsumer
+	var pending []utils.PendingEntry
+	var pendingTotal int64
+	group, consumer := "", ""
+
+	// message fills the table with a single line when there is nothing to list
+	message := func(text string) {
+		view.table.SetCell(1, 0, tview.NewTableCell(text).
+			SetTextColor(tcell.ColorGray).
+			SetSelectable(false))
+	}
+
+	render := func() {
+		var tabs []string
+		for i, name := range streamTabs {
+			if i == mode {
+				tabs = append(tabs, fmt.Sprintf("[black:yellow] %d %s [-:-]", i+1, name))
+			} else {
+				tabs = append(tabs, fmt.Sprintf("[gray] %d %s [white]", i+1, name))
+			}
+		}
+		summary := fmt.Sprintf("[yellow]%d entries[white] · %d groups", info.Length, info.Groups)
+		if info.FirstID != "" {
+			summary += fmt.Sprintf(" · first %s · last %s", info.FirstID, info.LastID)
+		}
+		if info.EntriesAdded > 0 {
+			summary += fmt.Sprintf(" · %d added", info.EntriesAdded)
+		}
+		if info.LastGeneratedID != "" && info.LastGeneratedID != info.LastID {
+			summary += fmt.Sprintf(" · last generated %s", info.LastGeneratedID)
+		}
+		if group != "" && mode >= streamConsumers {
+			summary += fmt.Sprintf(" · group [green]%s[white]", tview.Escape(group))
+		}
+		view.header.SetText(strings.Join(tabs, " ") + "\n" + summary)
+
+		view.table.Clear()
+		switch mode {
+		case streamEntries:
+			view.setColumns("ID", "Time", "Fields")
+			for i, entry := range page.Entries {
+				view.table.SetCell(i+1, 0, tview.NewTableCell(entry.ID).SetTextColor(tcell.ColorGreen))
+				view.table.SetCell(i+1, 1, tview.NewTableCell(entryTime(entry.ID)).SetTextColor(tcell.ColorGray))
+				view.table.SetCell(i+1, 2, tview.NewTableCell(entryFields(entry, " ", false)).
+					SetMaxWidth(80).
+					SetExpansion(1))
+			}
+			if len(page.Entries) == 0 {
+				message("The stream is empty")
+			}
+
+		case streamGroups:
+			view.setColumns("Group", "Consumers", "Pending", "Last delivered", "Read", "Lag")
+			for i, g := range groups {
+				view.table.SetCell(i+1, 0, tview.NewTableCell(cellText(g.Name)).SetTextColor(tcell.ColorGreen))
+				view.table.SetCell(i+1, 1, tview.NewTableCell(strconv.FormatInt(g.Consumers, 10)).SetAlign(tview.AlignRight))
+				view.table.SetCell(i+1, 2, tview.NewTableCell(strconv.FormatInt(g.Pending, 10)).SetAlign(tview.AlignRight))
+				view.table.SetCell(i+1, 3, tview.NewTableCell(g.LastDeliveredID))
+				view.table.SetCell(i+1, 4, tview.NewTableCell(strconv.FormatInt(g.EntriesRead, 10)).SetAlign(tview.AlignRight))
+				view.table.SetCell(i+1, 5, tview.NewTableCell(strconv.FormatInt(g.Lag, 10)).SetAlign(tview.AlignRight))
+			}
+			if len(groups) == 0 {
+				message("The stream has no consumer groups")
+			}
+
+		case streamConsumers:
+			view.setColumns("Consumer", "Pending", "Idle", "Inactive")
+			for i, c := range consumers {
+				view.table.SetCell(i+1, 0, tview.NewTableCell(cellText(c.Name)).SetTextColor(tcell.ColorGreen))
+				view.table.SetCell(i+1, 1, tview.NewTableCell(strconv.FormatInt(c.Pending, 10)).SetAlign(tview.AlignRight))
+				view.table.SetCell(i+1, 2, tview.NewTableCell(formatDuration(c.Idle)).SetAlign(tview.AlignRight))
+				inactive := "-"
+				if c.Inactive > 0 {
+					inactive = formatDuration(c.Inactive)
+				}
+				view.table.SetCell(i+1, 3, tview.NewTableCell(inactive).SetAlign(tview.AlignRight))
+			}
+			if group == "" {
+				message("The stream has no consumer groups")
+			} else if len(consumers) == 0 {
+				message("The group has no consumers")
+			}
+
+		case streamPending:
+			view.setColumns("ID", "Consumer", "Idle", "Deliveries")
+			for i, p := range pending {
+				view.table.SetCell(i+1, 0, tview.NewTableCell(p.ID).SetTextColor(tcell.ColorGreen))
+				view.table.SetCell(i+1, 1, tview.NewTableCell(cellText(p.Consumer)))
+				view.table.SetCell(i+1, 2, tview.NewTableCell(formatDuration(p.Idle)).SetAlign(tview.AlignRight))
+				view.table.SetCell(i+1, 3, tview.NewTableCell(strconv.FormatInt(p.Deliveries, 10)).SetAlign(tview.AlignRight))
+			}
+			switch {
+			case group == "":
+				message("The stream has no consumer groups")
+			case len(pending) == 0:
+				message("No pending entries")
+			case pendingTotal > int64(len(pending)):
+				view.header.SetText(view.header.GetText(false) +
+					fmt.Sprintf(" · oldest %d of %d pending", len(pending), pendingTotal))
+			}
+			if consumer != "" {
+				view.header.SetText(view.header.GetText(false) +
+					fmt.Sprintf(" · consumer [green]%s[white]", tview.Escape(consumer)))
+			}
+		}
+	}
+
+	// load reads the summary and tab in the background, then shows tab,
+	// selecting row (or keeping the selection when row is 0), and calls
+	// then (when set) with the outcome. It reports whether the read could
+	// be started.
+	load := func(tab int, row int, then func(err error)) bool {
+		start, reverse, readGroup, consumer := starts[len(starts)-1], reverse, group, consumer
+		return runner.Start("stream", func(conn *utils.RedisConnection) func() {
+			var nextPage *utils.StreamPage
+			var nextGroups []utils.StreamGroup
+			var nextConsumers []utils.StreamConsumer
+			var nextPending []utils.PendingEntry
+			var nextTotal int64
+
+			next, err := conn.GetStreamInfo(key)
+			if err == nil {
+				switch tab {
+				case streamEntries:
+					nextPage, err = conn.StreamRange(key, start, reverse)
+				case streamGroups:
+					nextGroups, err = conn.StreamGroups(key)
+				default:
+					if readGroup == "" {
+						// Consumers and pending entries belong to a group, take the first
+						if nextGroups, err = conn.StreamGroups(key); err == nil && len(nextGroups) > 0 {
+							readGroup = nextGroups[0].Name
+						}
+					}
+					if err == nil && readGroup != "" {
+						if tab == streamConsumers {
+							nextConsumers, err = conn.StreamConsumers(key, readGroup)
+						} else {
+							nextPending, nextTotal, err = conn.StreamPending(key, readGroup, consumer)
+						}
+					}
+				}
+			}
+
+			return func() {
+				if err != nil {
+					if !isCancelled(err) {
+						view.showError(err)
+					}
+					if then != nil {
+						then(err)
+					}
+					return
+				}
+
+				info = next
+				switch tab {
+				case streamEntries:
+					page = nextPage
+				case streamGroups:
+					groups = nextGroups
+				default:
+					if nextGroups != nil {
+						groups = nextGroups
+					}
+					group = readGroup
+					consumers, pending, pendingTotal = nextConsumers, nextPending, nextTotal
+				}
+
+				if row == 0 {
+					row, _ = view.table.GetSelection()
+				}
+				mode = tab
+				view.hints = streamHints[mode]
+				render()
+				view.restoreSelection(row)
+				view.footer.SetText(view.hints)
+				if then != nil {
+					then(nil)
+				}
+			}
+		})
+	}
+
+	switchTab := func(tab int) {
+		load(tab, 1, nil)
+	}
+
+	// showEntry opens the full fields of an entry over the table
+	showEntry := func(id string) {
+		runner.Start("entry", func(conn *utils.RedisConnection) func() {
+			page, err := conn.StreamRange(key, id, false)
+			return func() {
+				if isCancelled(err) {
+					return
+				} else if err != nil {
+					view.showError(err)
+					return
+				}
+				if len(page.Entries) == 0 || page.Entries[0].ID != id {
+					view.showError(fmt.Errorf("entry %s no longer exists", id))
+					return
+				}
+				entry := page.Entries[0]
+
+				details := tview.NewTextView().SetDynamicColors(true).SetWrap(true)
+				details.SetBorder(true).SetTitle(fmt.Sprintf(" %s · %s ", id, entryTime(id)))
+				details.SetText(entryFields(entry, "\n", true))
+				details.SetDoneFunc(func(key tcell.Key) {
+					view.closeOverlay()
+				})
+				view.showOverlay(details, 70, len(entry.Fields)+2)
+			}
+		})
+	}
+
+	addEntry := func() {
+		form := tview.NewForm()
+		form.SetBorder(true).SetTitle(" XADD ")
+		idInput := tview.NewInputField().SetLabel("ID: ").SetText("*").SetFieldWidth(30)
+		fieldsInput := tview.NewTextArea().SetLabel("Fields: ").SetSize(6, 40).
+			SetPlaceholder("field=value, one per line")
+		form.AddFormItem(idInput)
+		form.AddFormItem(fieldsInput)
+		form.AddButton("Add", func() {
+			fields, err := parseEntryFields(fieldsInput.GetText())
+			if err != nil {
+				view.log("[red]Error:[white] %v", err)
+				return
+			}
+			id := strings.TrimSpace(idInput.GetText())
+			if id == "*" {
+				id = ""
+			}
+			runner.Start("XADD", func(conn *utils.RedisConnection) func() {
+				added, err := conn.StreamAdd(key, id, fields)
+				return func() {
+					if isCancelled(err) {
+						return
+					} else if err != nil {
+						view.log("[red]Error adding entry:[white] %v", err)
+						return
+					}
+					view.log("[green]Added entry %s to '%s'[white]", added, tview.Escape(key))
+					view.closeOverlay()
+					load(mode, 0, nil)
+				}
+			})
+		})
+		form.AddButton("Cancel", view.closeOverlay)
+		view.showForm(form, 60, 13)
+	}
+
+	trimStream := func() {
+		form := tview.NewForm()
+		form.SetBorder(true).SetTitle(fmt.Sprintf(" XTRIM (%d entries) ", info.Length))
+		thresholdInput := tview.NewInputField().SetLabel("Keep: ").SetFieldWidth(30)
+		approx := tview.NewCheckbox().SetLabel("Approximate (~): ")
+		form.AddFormItem(thresholdInput)
+		form.AddTextView("", "a number keeps the newest entries (MAXLEN), an entry ID keeps it and newer ones (MINID)", 40, 2, true, false)
+		form.AddFormItem(approx)
+		form.AddButton("Trim", func() {
+			threshold, approximate := thresholdInput.GetText(), approx.IsChecked()
+			runner.Start("XTRIM", func(conn *utils.RedisConnection) func() {
+				removed, err := conn.StreamTrim(key, threshold, approximate)
+				return func() {
+					if isCancelled(err) {
+						return
+					} else if err != nil {
+						view.log("[red]Error trimming:[white] %v", err)
+						return
+					}
+					view.log("[green]Trimmed %d entries from '%s'[white]", removed, tview.Escape(key))
+					view.closeOverlay()
+					load(mode, 0, nil)
+				}
+			})
+		})
+		form.AddButton("Cancel", view.closeOverlay)
+		view.showForm(form, 60, 12)
+	}
+
+	selectedPending := func() (utils.PendingEntry, bool) {
+		i := view.selected(len(pending))
+		if i < 0 || mode != streamPending {
+			return utils.PendingEntry{}, false
+		}
+		return pending[i], true
+	}
+
+	ackEntry := func() {
+		p, ok := selectedPending()
+		if !ok {
+			return
+		}
+		group := group
+		view.confirm(fmt.Sprintf("Acknowledge entry %s in group '%s'?", p.ID, group), func() {
+			runner.Start("XACK", func(conn *utils.RedisConnection) func() {
+				acked, err := conn.StreamAck(key, group, p.ID)
+				return func() {
+					if isCancelled(err) {
+						return
+					} else if err != nil {
+						view.log("[red]Error acknowledging:[white] %v", err)
+						return
+					}
+					if acked == 0 {
+						view.log("[yellow]Entry %s was no longer pending in '%s'[white]", p.ID, tview.Escape(group))
+					} else {
+						view.log("[green]Acknowledged entry %s in group '%s'[white]", p.ID, tview.Escape(group))
+					}
+					load(mode, 0, nil)
+				}
+			})
+		})
+	}
+
+	claimEntry := func() {
+		p, ok := selectedPending()
+		if !ok {
+			return
+		}
+		form := tview.NewForm()
+		form.SetBorder(true).SetTitle(fmt.Sprintf(" XCLAIM %s ", p.ID))
+		consumerInput := tview.NewInputField().SetLabel("Consumer: ").SetFieldWidth(30)
+		idleInput := tview.NewInputField().
+			SetLabel("Min idle (ms): ").
+			SetText("0").
+			SetFieldWidth(12).
+			SetAcceptanceFunc(tview.InputFieldInteger)
+		form.AddFormItem(consumerInput)
+		form.AddFormItem(idleInput)
+		form.AddButton("Claim", func() {
+			target := strings.TrimSpace(consumerInput.GetText())
+			if target == "" {
+				view.log("[red]Error:[white] the consumer to claim for is required")
+				return
+			}
+			idle, err := strconv.ParseInt(idleInput.GetText(), 10, 64)
+			if err != nil || idle < 0 {
+				view.log("[red]Error:[white] the minimum idle time must be a number of milliseconds")
+				return
+			}
+			group := group
+			runner.Start("XCLAIM", func(conn *utils.RedisConnection) func() {
+				claimed, err := conn.StreamClaim(key, group, target, time.Duration(idle)*time.Millisecond, p.ID)
+				return func() {
+					if isCancelled(err) {
+						return
+					} else if err != nil {
+						view.log("[red]Error claiming:[white] %v", err)
+						return
+					}
+					if len(claimed) == 0 {
+						view.log("[yellow]Entry %s was not claimed: it is no longer pending or was idle for less than %dms[white]", p.ID, idle)
+					} else {
+						view.log("[green]Claimed entry %s for consumer '%s'[white]", p.ID, tview.Escape(target))
+					}
+					view.closeOverlay()
+					load(mode, 0, nil)
+				}
+			})
+		})
+		form.AddButton("Cancel", view.closeOverlay)
+		view.showForm(form, 60, 9)
+	}
+
+	view.table.SetSelectedFunc(func(row, column int) {
+		switch mode {
+		case streamEntries:
+			if i := view.selected(len(page.Entries)); i >= 0 {
+				showEntry(page.Entries[i].ID)
+			}
+		case streamGroups:
+			if i := view.selected(len(groups)); i >= 0 {
+				group, consumer = groups[i].Name, ""
+				switchTab(streamPending)
+			}
+		case streamConsumers:
+			if i := view.selected(len(consumers)); i >= 0 {
+				consumer = consumers[i].Name
+				switchTab(streamPending)
+			}
+		case streamPending:
+			if p, ok := selectedPending(); ok {
+				showEntry(p.ID)
+			}
+		}
+	})
+
+	// The view opens once the summary and first page are in
+	onKey := func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab {
+			switchTab((mode + 1) % len(streamTabs))
+			return nil
+		}
+		if r := event.Rune(); r >= '1' && r < '1'+rune(len(streamTabs)) {
+			switchTab(int(r - '1'))
+			return nil
+		}
+
+		switch event.Rune() {
+		case 'r':
+			load(mode, 0, nil)
+			return nil
+		case 'a':
+			if mode <= streamGroups {
+				addEntry()
+				return nil
+			}
+		case 't':
+			if mode <= streamGroups {
+				trimStream()
+				return nil
+			}
+		}
+
+		switch mode {
+		case streamEntries:
+			switch event.Rune() {
+			case 'n':
+				if page.Next != "" {
+					starts = append(starts, page.Next)
+					// Stay on this page if the next one cannot be read
+					if !load(mode, 1, func(err error) {
+						if err != nil {
+							starts = starts[:len(starts)-1]
+						}
+					}) {
+						starts = starts[:len(starts)-1]
+					}
+				}
+			case 'p':
+				if len(starts) > 1 {
+					starts = starts[:len(starts)-1]
+					load(mode, 1, nil)
+				}
+			case 'o':
+				reverse = !reverse
+				starts = []string{""}
+				load(mode, 1, nil)
+			case 'g':
+				view.prompt("Go to ID (or a Unix time in ms): ", "", func(text string) {
+					if text = strings.TrimSpace(text); text != "" {
+						starts = []string{"", text}
+						load(mode, 1, nil)
+					}
+				})
+			default:
+				return event
+			}
+			return nil
+
+		case streamPending:
+			switch event.Rune() {
+			case 'k':
+				ackEntry()
+			case 'c':
+				claimEntry()
+			case 'f':
+				consumer = ""
+				load(mode, 1, nil)
+			default:
+				return event
+			}
+			return nil
+		}
+		return event
+	}
+	load(mode, 1, func(err error) {
+		if err != nil {
+			if !isCancelled(err) {
+				view.log("[red]Error reading stream '%s':[white] %v", tview.Escape(key), err)
+			}
+			return
+		}
+		view.open(onKey)
+	})
+}
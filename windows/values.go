@@ -0,0 +1,182 @@
+package windows
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Amrit02102004/RediCLI/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// prettyJSON indents value when it holds a JSON object or array, keeping the
+// original key order; anything else is returned unchanged
+func prettyJSON(value string) string {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return value
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(trimmed), "", "  "); err != nil {
+		return value
+	}
+	return out.String()
+}
+
+// FormatValue renders a typed Redis value for a TextView with dynamic colors
+func FormatValue(value *utils.Value) string {
+	var sb strings.Builder
+
+	switch value.Type {
+	case "string":
+		sb.WriteString(tview.Escape(prettyJSON(value.String)))
+		sb.WriteString("\n")
+
+	case "hash":
+		for _, f := range value.Hash {
+			sb.WriteString(fmt.Sprintf("[green]%s[white]: %s\n", tview.Escape(f.Field), tview.Escape(f.Value)))
+		}
+
+	case "list":
+		for i, item := range value.List {
+			sb.WriteString(fmt.Sprintf("[gray]%d)[white] %s\n", i, tview.Escape(item)))
+		}
+
+	case "set":
+		for _, member := range value.Set {
+			sb.WriteString(fmt.Sprintf("• %s\n", tview.Escape(member)))
+		}
+
+	case "zset":
+		for _, m := range value.ZSet {
+			sb.WriteString(fmt.Sprintf("[green]%g[white]  %s\n", m.Score, tview.Escape(m.Member)))
+		}
+
+	case "stream":
+		for _, entry := range value.Stream {
+			sb.WriteString(fmt.Sprintf("[green]%s[white]\n", entry.ID))
+			fields := make([]string, 0, len(entry.Fields))
+			for field := range entry.Fields {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			for _, field := range fields {
+				sb.WriteString(fmt.Sprintf("  %s: %v\n", tview.Escape(field), tview.Escape(fmt.Sprint(entry.Fields[field]))))
+			}
+		}
+	}
+
+	if value.Type != "string" && value.Truncated() {
+		sb.WriteString(fmt.Sprintf("[gray]... showing %d of %d elements[white]\n", value.Count(), value.Length))
+	}
+
+	return sb.String()
+}
+
+// valueTypeLabel describes the type and size of a value, e.g. "hash (12 fields)"
+func valueTypeLabel(value *utils.Value) string {
+	switch value.Type {
+	case "string":
+		return fmt.Sprintf("string (%d bytes)", value.Length)
+	case "hash":
+		return fmt.Sprintf("hash (%d fields)", value.Length)
+	case "stream":
+		return fmt.Sprintf("stream (%d entries)", value.Length)
+	}
+	return fmt.Sprintf("%s (%d elements)", value.Type, value.Length)
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. 1.5 KB
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// typeColors gives every Redis type its own colour in the key browser
+var typeColors = map[string]tcell.Color{
+	"string": tcell.ColorGreen,
+	"hash":   tcell.ColorDodgerBlue,
+	"list":   tcell.ColorYellow,
+	"set":    tcell.ColorOrchid,
+	"zset":   tcell.ColorOrange,
+	"stream": tcell.ColorAqua,
+}
+
+// typeColor returns the colour of a Redis type, white for unknown ones
+func typeColor(kind string) tcell.Color {
+	if color, ok := typeColors[kind]; ok {
+		return color
+	}
+	return tcell.ColorWhite
+}
+
+// formatDuration renders d with its two largest units, e.g. 3h12m or 2d4h
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+
+	units := []struct {
+		size   time.Duration
+		suffix string
+	}{
+		{24 * time.Hour, "d"},
+		{time.Hour, "h"},
+		{time.Minute, "m"},
+		{time.Second, "s"},
+	}
+
+	var sb strings.Builder
+	parts := 0
+	for _, u := range units {
+		if n := d / u.size; n > 0 || parts > 0 {
+			if n > 0 {
+				sb.WriteString(fmt.Sprintf("%d%s", n, u.suffix))
+			}
+			d -= n * u.size
+			parts++
+			if parts == 2 {
+				break
+			}
+		}
+	}
+	return sb.String()
+}
+
+// formatTTL renders a remaining TTL, with millisecond precision under a
+// minute and as a human duration such as 3h12m above
+func formatTTL(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return formatDuration(d)
+}
+
+// ttlColor highlights keys that are about to expire
+func ttlColor(d time.Duration) tcell.Color {
+	switch {
+	case d <= 10*time.Second:
+		return tcell.ColorRed
+	case d <= time.Minute:
+		return tcell.ColorOrange
+	case d <= 10*time.Minute:
+		return tcell.ColorYellow
+	}
+	return tcell.ColorWhite
+}
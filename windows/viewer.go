@@ -0,0 +1,234 @@
+package windows
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Amrit02102004/RediCLI/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// maxHexDump is how many bytes the hex view shows.
+const maxHexDump = 64 << 10
+
+// Viewer modes, in the order of their number keys
+const (
+	viewText = iota
+	viewJSON
+	viewHex
+	viewDecoded
+)
+
+var viewNames = []string{"Text", "JSON tree", "Hex", "Decoded"}
+
+// ValueViewer shows a key's value in place of the display pane, switchable
+// between plain text, a foldable JSON tree, a hex dump and an auto-decoded
+// view that peels off base64 and gzip and parses JSON or MessagePack.
+type ValueViewer struct {
+	app   *tview.Application
+	redis *utils.RedisConnection
+	pane  *DisplayPane
+}
+
+func NewValueViewer(app *tview.Application, redis *utils.RedisConnection, pane *DisplayPane) *ValueViewer {
+	return &ValueViewer{
+		app:   app,
+		redis: redis,
+		pane:  pane,
+	}
+}
+
+// Show opens the viewer on value, read from key, starting with the decoded
+// view when the value has a recognised encoding.
+func (v *ValueViewer) Show(key string, value *utils.Value) {
+	// Collections are viewed as their JSON rendering
+	raw := []byte(value.Text())
+	decoded := utils.Decode(raw)
+
+	header := tview.NewTextView().SetDynamicColors(true)
+	body := tview.NewTextView().SetDynamicColors(true).SetWrap(true)
+	tree := tview.NewTreeView()
+	pages := tview.NewPages().
+		AddPage("text", body, true, true).
+		AddPage("tree", tree, true, false)
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(header, 3, 0, false).
+		AddItem(pages, 0, 1, true)
+	layout.SetBorder(true).SetTitle(fmt.Sprintf(" %s ", tview.Escape(key)))
+
+	mode := viewText
+	if len(decoded.Steps) > 0 {
+		mode = viewDecoded
+	} else if decoded.Format != "" {
+		mode = viewJSON
+	}
+
+	render := func() {
+		var tabs []string
+		for i, name := range viewNames {
+			if i == mode {
+				tabs = append(tabs, fmt.Sprintf("[black:yellow] %d %s [-:-]", i+1, name))
+			} else {
+				tabs = append(tabs, fmt.Sprintf("[gray] %d %s [white]", i+1, name))
+			}
+		}
+		info := fmt.Sprintf("[yellow]%s[white]", valueTypeLabel(value))
+		if value.Type != "string" {
+			info += fmt.Sprintf(" · viewed as %d bytes of JSON", len(raw))
+		}
+		if len(decoded.Steps) > 0 || decoded.Format != "" {
+			chain := append(append([]string{}, decoded.Steps...), decoded.Format)
+			info += " · decoded: " + strings.Trim(strings.Join(chain, " → "), " →")
+		}
+		if decoded.Truncated {
+			info += fmt.Sprintf(" · [red]gzip output truncated to the first %d MB[white]", utils.MaxDecompressed>>20)
+		}
+		header.SetText(strings.Join(tabs, " ") + "\n" + info + "\n[gray]1-4/Tab switch view · Enter folds JSON nodes · Esc closes[white]")
+
+		switch mode {
+		case viewText:
+			pages.SwitchToPage("text")
+			body.SetText(textView(raw))
+		case viewHex:
+			pages.SwitchToPage("text")
+			body.SetText(hexView(raw))
+		case viewJSON:
+			if parsed, ok := jsonValue(raw); ok {
+				pages.SwitchToPage("tree")
+				tree.SetRoot(jsonTree("value", parsed)).SetCurrentNode(tree.GetRoot())
+			} else {
+				pages.SwitchToPage("text")
+				body.SetText("[gray]The value is not JSON[white]")
+			}
+		case viewDecoded:
+			if decoded.Format != "" {
+				pages.SwitchToPage("tree")
+				tree.SetRoot(jsonTree(decoded.Format, decoded.Value)).SetCurrentNode(tree.GetRoot())
+			} else if len(decoded.Steps) > 0 {
+				pages.SwitchToPage("text")
+				if utils.IsReadable(decoded.Data) {
+					body.SetText(textView(decoded.Data))
+				} else {
+					body.SetText(hexView(decoded.Data))
+				}
+			} else {
+				pages.SwitchToPage("text")
+				body.SetText("[gray]No base64, gzip, JSON or MessagePack encoding detected[white]")
+			}
+		}
+		body.ScrollToBeginning()
+	}
+	render()
+
+	tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		node.SetExpanded(!node.IsExpanded())
+	})
+
+	layout.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape:
+			v.pane.Close()
+			return nil
+		case event.Key() == tcell.KeyTab:
+			mode = (mode + 1) % len(viewNames)
+			render()
+			return nil
+		case event.Rune() >= '1' && event.Rune() < '1'+rune(len(viewNames)):
+			mode = int(event.Rune() - '1')
+			render()
+			return nil
+		}
+		return event
+	})
+
+	v.pane.Show(layout)
+}
+
+// textView renders raw as text, quoting it with escapes when it is binary
+func textView(raw []byte) string {
+	if utils.IsReadable(raw) {
+		return tview.Escape(string(raw))
+	}
+	quoted := strconv.Quote(string(raw))
+	return "[gray](binary, shown quoted)[white]\n" + tview.Escape(quoted[1:len(quoted)-1])
+}
+
+// hexView renders raw as a hex dump with offsets and ASCII
+func hexView(raw []byte) string {
+	data := raw
+	note := ""
+	if len(data) > maxHexDump {
+		data = data[:maxHexDump]
+		note = fmt.Sprintf("\n[gray]... showing the first %d of %d bytes[white]", maxHexDump, len(raw))
+	}
+	return tview.Escape(hex.Dump(data)) + note
+}
+
+// jsonValue parses raw when it holds a JSON value
+func jsonValue(raw []byte) (interface{}, bool) {
+	decoded := utils.Decode(raw)
+	if len(decoded.Steps) == 0 && decoded.Format == "json" {
+		return decoded.Value, true
+	}
+	return nil, false
+}
+
+// jsonTree builds a foldable tree of a parsed JSON or MessagePack value.
+// Containers show their size; nodes below the second level start folded.
+func jsonTree(label string, value interface{}) *tview.TreeNode {
+	return jsonNode(label, value, 0)
+}
+
+func jsonNode(label string, value interface{}, depth int) *tview.TreeNode {
+	name := fmt.Sprintf("[green]%s[white]", tview.Escape(label))
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		node := tview.NewTreeNode(fmt.Sprintf("%s {%d}", name, len(v))).SetExpanded(depth < 2)
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			node.AddChild(jsonNode(key, v[key], depth+1))
+		}
+		return node
+
+	case []interface{}:
+		node := tview.NewTreeNode(fmt.Sprintf("%s [%d]", name, len(v))).SetExpanded(depth < 2)
+		for i, item := range v {
+			node.AddChild(jsonNode(fmt.Sprintf("[%d]", i), item, depth+1))
+		}
+		return node
+	}
+
+	return tview.NewTreeNode(fmt.Sprintf("%s: %s", name, jsonScalar(value))).SetSelectable(true)
+}
+
+// jsonScalar renders a leaf value, coloured by kind
+func jsonScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "[gray]null[white]"
+	case string:
+		quoted, _ := json.Marshal(v)
+		return fmt.Sprintf("[yellow]%s[white]", tview.Escape(string(quoted)))
+	case bool:
+		return fmt.Sprintf("[orange]%t[white]", v)
+	case json.Number, int64, uint64, float64:
+		return fmt.Sprintf("[aqua]%v[white]", v)
+	case []byte:
+		return fmt.Sprintf("[purple]bin %s[white]", hex.EncodeToString(v))
+	case time.Time:
+		return fmt.Sprintf("[purple]%s[white]", v.Format(time.RFC3339Nano))
+	}
+	return tview.Escape(fmt.Sprintf("%v", value))
+}
@@ -1,9 +1,14 @@
 package windows
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"regexp"
 	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/Amrit02102004/RediCLI/utils"
@@ -12,170 +17,1265 @@ import (
 )
 
 type KeyData struct {
-	key    string
-	value  string
-	ttl    string
-	memory int64
+	key      string
+	kind     string // Redis type of the key
+	encoding string
+	value    string
+	expires  time.Time     // when the key expires, zero for keys without a TTL
+	idle     time.Duration // -1 when the server does not track it
+	memory   int64
+	count    int64 // STRLEN for strings, element count otherwise
+	node     string
+	pinned   bool   // added by jump-to-key rather than by the page scan
+	gone     string // "expired" or "deleted" when the key disappeared since the last refresh
 }
 
-func Win1(app *tview.Application, redis *utils.RedisConnection, kvDisplay *tview.TextView) *tview.Flex {
-	mainFlex := tview.NewFlex().SetDirection(tview.FlexRow)
+// remaining returns the TTL left on the key as of now
+func (d KeyData) remaining() time.Duration {
+	return time.Until(d.expires)
+}
 
-	table := tview.NewTable().
-		SetBorders(true).
-		SetFixed(1, 0).
-		SetSeparator(tview.Borders.Vertical)
+// Fields the key table can be sorted by
+const (
+	sortByKey = iota
+	sortByType
+	sortByTTL
+	sortByMemory
+	sortByCount
+	sortByIdle
+)
+
+var sortNames = []string{"key", "type", "TTL", "memory", "count", "idle"}
+
+// sortKeyData orders rows by the given field, breaking ties by key so the
+// order is stable across refreshes. Keys without a TTL sort as the longest
+// lived.
+func sortKeyData(rows []KeyData, by int, desc bool) {
+	ttlOf := func(d KeyData) int64 {
+		if d.expires.IsZero() {
+			return math.MaxInt64
+		}
+		return d.expires.UnixNano()
+	}
+	less := func(a, b KeyData) bool {
+		switch by {
+		case sortByType:
+			if a.kind != b.kind {
+				return a.kind < b.kind
+			}
+		case sortByTTL:
+			if ttlOf(a) != ttlOf(b) {
+				return ttlOf(a) < ttlOf(b)
+			}
+		case sortByMemory:
+			if a.memory != b.memory {
+				return a.memory < b.memory
+			}
+		case sortByCount:
+			if a.count != b.count {
+				return a.count < b.count
+			}
+		case sortByIdle:
+			if a.idle != b.idle {
+				return a.idle < b.idle
+			}
+		}
+		return a.key < b.key
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if desc {
+			return less(rows[j], rows[i])
+		}
+		return less(rows[i], rows[j])
+	})
+}
 
-	headerCells := []string{"Key", "Value", "TTL", "Memory"}
-	for i, header := range headerCells {
-		cell := tview.NewTableCell(header).
+// keyColumn describes one column of the key table
+type keyColumn struct {
+	title     string
+	maxWidth  int
+	expansion int
+	sortBy    int // -1 when the column cannot be sorted by
+	cell      func(data KeyData) *tview.TableCell
+}
+
+var keyColumns = []keyColumn{
+	{"Key", 15, 1, sortByKey, func(data KeyData) *tview.TableCell {
+		key := data.key
+		if data.pinned {
+			key = "→ " + key
+		}
+		if data.gone != "" {
+			return tview.NewTableCell(key).
+				SetTextColor(tcell.ColorGray).
+				SetAttributes(tcell.AttrStrikeThrough)
+		}
+		return tview.NewTableCell(key)
+	}},
+	{"Type", 6, 1, sortByType, func(data KeyData) *tview.TableCell {
+		return tview.NewTableCell(data.kind).SetTextColor(typeColor(data.kind))
+	}},
+	{"Value", 30, 2, -1, func(data KeyData) *tview.TableCell {
+		// Truncate value for table display
+		const maxValueLength = 30
+		displayValue := data.value
+		if len(displayValue) > maxValueLength {
+			displayValue = displayValue[:maxValueLength] + "..."
+		}
+		return tview.NewTableCell(displayValue)
+	}},
+	{"TTL", 10, 1, sortByTTL, func(data KeyData) *tview.TableCell {
+		// The TTL counts down locally between refreshes
+		switch {
+		case data.gone != "":
+			return tview.NewTableCell(data.gone).SetTextColor(tcell.ColorRed)
+		case data.expires.IsZero():
+			return tview.NewTableCell("∞").SetTextColor(tcell.ColorGray)
+		}
+		remaining := data.remaining()
+		if remaining <= 0 {
+			return tview.NewTableCell("expiring").SetTextColor(tcell.ColorRed)
+		}
+		return tview.NewTableCell(formatTTL(remaining)).SetTextColor(ttlColor(remaining))
+	}},
+	{"Memory", 10, 1, sortByMemory, func(data KeyData) *tview.TableCell {
+		return tview.NewTableCell(fmt.Sprintf("%d B", data.memory))
+	}},
+	{"Count", 8, 1, sortByCount, func(data KeyData) *tview.TableCell {
+		return tview.NewTableCell(fmt.Sprintf("%d", data.count)).SetAlign(tview.AlignRight)
+	}},
+	{"Encoding", 10, 1, -1, func(data KeyData) *tview.TableCell {
+		return tview.NewTableCell(data.encoding).SetTextColor(tcell.ColorGray)
+	}},
+	{"Idle", 8, 1, sortByIdle, func(data KeyData) *tview.TableCell {
+		if data.idle < 0 {
+			return tview.NewTableCell("-").SetTextColor(tcell.ColorGray)
+		}
+		return tview.NewTableCell(formatDuration(data.idle))
+	}},
+}
+
+var nodeColumn = keyColumn{"Node", 21, 1, -1, func(data KeyData) *tview.TableCell {
+	return tview.NewTableCell(data.node)
+}}
+
+// keyTable is the virtual content of the key table. tview asks it for cells
+// while drawing, so only the rows on screen are ever turned into cells, no
+// matter how many keys the page holds.
+type keyTable struct {
+	tview.TableContentReadOnly
+	rows     []KeyData
+	cluster  bool
+	err      error
+	sortBy   int
+	sortDesc bool
+}
+
+// columns returns the visible columns, adding Node for clusters
+func (t *keyTable) columns() []keyColumn {
+	if t.cluster {
+		return append(keyColumns[:len(keyColumns):len(keyColumns)], nodeColumn)
+	}
+	return keyColumns
+}
+
+// columnSort returns the sort field of a column, or -1 if it cannot be
+// sorted by
+func (t *keyTable) columnSort(column int) int {
+	columns := t.columns()
+	if column < 0 || column >= len(columns) {
+		return -1
+	}
+	return columns[column].sortBy
+}
+
+func (t *keyTable) GetRowCount() int {
+	if t.err != nil {
+		return 2
+	}
+	return len(t.rows) + 1
+}
+
+func (t *keyTable) GetColumnCount() int {
+	return len(t.columns())
+}
+
+func (t *keyTable) GetCell(row, column int) *tview.TableCell {
+	columns := t.columns()
+	if column < 0 || column >= len(columns) {
+		return nil
+	}
+	col := columns[column]
+
+	if row == 0 {
+		header := col.title
+		if col.sortBy >= 0 && col.sortBy == t.sortBy {
+			if t.sortDesc {
+				header += " ▼"
+			} else {
+				header += " ▲"
+			}
+		}
+		return tview.NewTableCell(header).
 			SetTextColor(tcell.ColorYellow).
 			SetAlign(tview.AlignCenter).
-			SetSelectable(false)
+			SetSelectable(false).
+			SetMaxWidth(col.maxWidth).
+			SetExpansion(col.expansion)
+	}
 
-		switch i {
-		case 0: // Key
-			cell.SetMaxWidth(15).SetExpansion(1)
-		case 1: // Value
-			cell.SetMaxWidth(30).SetExpansion(2)
-		case 2: // TTL
-			cell.SetMaxWidth(10).SetExpansion(1)
-		case 3: // Memory
-			cell.SetMaxWidth(10).SetExpansion(1)
+	if t.err != nil {
+		if row == 1 && column == 0 {
+			return tview.NewTableCell(fmt.Sprintf("Error: %v", t.err)).
+				SetTextColor(tcell.ColorRed)
 		}
+		return nil
+	}
 
-		table.SetCell(0, i, cell)
+	if row-1 >= len(t.rows) {
+		return nil
 	}
+	return col.cell(t.rows[row-1]).
+		SetMaxWidth(col.maxWidth).
+		SetExpansion(col.expansion)
+}
 
-	refreshTableData := func() {
-		for i := table.GetRowCount() - 1; i > 0; i-- {
-			table.RemoveRow(i)
+// indexOf returns the row index of key, or -1
+func (t *keyTable) indexOf(key string) int {
+	for i, data := range t.rows {
+		if data.key == key {
+			return i
 		}
+	}
+	return -1
+}
 
-		if !redis.IsConnected() {
+// vanishedReason tells whether a key that disappeared expired, which is
+// only assumed when its last known TTL has run out, or was deleted
+func vanishedReason(data KeyData) string {
+	if !data.expires.IsZero() && data.remaining() <= 0 {
+		return "expired"
+	}
+	return "deleted"
+}
+
+// droppedKeys returns the keys of old that are missing from rows, leaving
+// out rows flagged or pinned before. A key missing from a new SCAN page may
+// just have moved to another page, so they have to be checked before being
+// flagged.
+func droppedKeys(old, rows []KeyData) []string {
+	present := make(map[string]struct{}, len(rows))
+	for _, data := range rows {
+		present[data.key] = struct{}{}
+	}
+	var keys []string
+	for _, data := range old {
+		if _, ok := present[data.key]; ok || data.gone != "" || data.pinned {
+			continue
+		}
+		keys = append(keys, data.key)
+	}
+	return keys
+}
+
+// flagVanished returns rows with the keys of old that are in missing, as
+// confirmed by the server, appended as flagged rows, so keys that expired
+// or were deleted since the last refresh are pointed out rather than
+// silently dropped. Rows flagged before are not carried over again.
+func flagVanished(old, rows []KeyData, missing map[string]bool) []KeyData {
+	present := make(map[string]struct{}, len(rows))
+	for _, data := range rows {
+		present[data.key] = struct{}{}
+	}
+	for _, data := range old {
+		if _, ok := present[data.key]; ok || data.gone != "" || data.pinned || !missing[data.key] {
+			continue
+		}
+		data.gone = vanishedReason(data)
+		rows = append(rows, data)
+	}
+	return rows
+}
+
+// defaultRefreshInterval is how often the visible rows are refreshed
+const defaultRefreshInterval = time.Second
+
+// keyDetailsTimeout bounds the reads behind the details of the selected key
+const keyDetailsTimeout = 5 * time.Second
+
+// parseRefreshInterval reads an auto-refresh interval such as 5s, where
+// "manual", "off" or 0 turn auto-refresh off
+func parseRefreshInterval(text string) (time.Duration, error) {
+	text = strings.TrimSpace(strings.ToLower(text))
+	switch text {
+	case "manual", "off", "0", "":
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(text)
+	if err != nil {
+		if seconds, convErr := strconv.Atoi(text); convErr == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second, nil
+		}
+		return 0, fmt.Errorf("invalid interval '%s', use a duration such as 5s or manual", text)
+	}
+	if interval < 100*time.Millisecond {
+		return 0, fmt.Errorf("the interval must be at least 100ms")
+	}
+	return interval, nil
+}
+
+// keyDataFromMeta turns fetched metadata into a table row. On a cluster it
+// asks which node holds the key, so it must not run on the UI goroutine.
+func keyDataFromMeta(redis *utils.RedisConnection, meta utils.KeyMeta, cluster bool) KeyData {
+	value := meta.Preview
+	if meta.Err != nil {
+		value = fmt.Sprintf("Error: %v", meta.Err)
+	} else if meta.Type != "string" {
+		value = fmt.Sprintf("(%d elements)", meta.Length)
+	} else if meta.Length > int64(len(meta.Preview)) {
+		value += "..."
+	}
+
+	node := ""
+	if cluster {
+		node, _ = redis.KeyNode(meta.Key)
+	}
+
+	var expires time.Time
+	if meta.TTL >= 0 {
+		expires = time.Now().Add(meta.TTL)
+	}
+
+	return KeyData{
+		key:      meta.Key,
+		kind:     meta.Type,
+		encoding: meta.Encoding,
+		value:    value,
+		expires:  expires,
+		idle:     meta.Idle,
+		memory:   meta.Memory,
+		count:    meta.Length,
+		node:     node,
+	}
+}
+
+func Win1(app *tview.Application, redis *utils.RedisConnection, kvDisplay *tview.TextView, logDisplay *tview.TextView, views *KeyViews) *tview.Flex {
+	mainFlex := tview.NewFlex().SetDirection(tview.FlexRow)
+
+	content := &keyTable{cluster: redis.IsCluster()}
+	table := tview.NewTable().
+		SetBorders(true).
+		SetFixed(1, 0).
+		SetSeparator(tview.Borders.Vertical).
+		SetContent(content)
+	table.SetSelectable(true, false)
+
+	footer := tview.NewTextView().SetDynamicColors(true)
+
+	// The table and its footer form the flat view, which 't' swaps for the
+	// namespace tree
+	tableView := tview.NewFlex().SetDirection(tview.FlexRow)
+	treeShown := false
+
+	// Sorting and filtering last for the whole session. Sorting applies to
+	// the loaded page; a glob filter is passed to SCAN MATCH while a regular
+	// expression is checked client side.
+	content.sortBy = sortByMemory
+	filter, filterRegex := "", false
+	var filterMatch func(key string) bool
+
+	// The pager is rebuilt whenever the keyspace it walks changes, since
+	// SCAN cursors are only meaningful for the database they came from
+	var pager *utils.KeyPager
+	pagerDB, pagerCluster := -1, false
+	currentPager := func() *utils.KeyPager {
+		if pager == nil || pagerDB != redis.DB() || pagerCluster != redis.IsCluster() {
+			opts := utils.ScanOptions{Filter: filterMatch}
+			if !filterRegex {
+				opts.Match = filter
+			}
+			pager = redis.NewKeyPager(opts, utils.DefaultPageSize)
+			pagerDB, pagerCluster = redis.DB(), redis.IsCluster()
+		}
+		return pager
+	}
+
+	// Page state as of the last load, kept on the UI goroutine so drawing
+	// never waits on a pager that is busy scanning
+	page, hasPrev, hasNext := 0, false, false
+
+	// pendingDelete holds the key waiting for a y/n confirmation, whose
+	// question stays in the footer until answered
+	pendingDelete := ""
+
+	updateFooter := func() {
+		if pendingDelete != "" {
 			return
 		}
+		if page == 0 {
+			footer.SetText("")
+			return
+		}
+		nav := "[gray]"
+		if hasPrev {
+			nav += "[white]p[gray] prev  "
+		}
+		if hasNext {
+			nav += "[white]n[gray] next  "
+		}
+		nav += "[white]a[gray] pause  [white]i[gray] interval  [white]enter[gray] actions  [white]f[gray] find key  [white]/[gray] filter  [white]s[gray] sort  [white]t[gray] tree  [white]r[gray] reload"
+
+		order := "▲"
+		if content.sortDesc {
+			order = "▼"
+		}
+		info := fmt.Sprintf("Page %d · %d keys · by %s %s", page, len(content.rows), sortNames[content.sortBy], order)
+		if filter != "" {
+			mode := "glob"
+			if filterRegex {
+				mode = "regex"
+			}
+			info += fmt.Sprintf(" · %s [green]%s[white]", mode, tview.Escape(filter))
+		}
+		footer.SetText(fmt.Sprintf("%s  %s[white]", info, nav))
+	}
 
-		keys, err := redis.GetAllKeys()
+	// fetchRows loads the metadata of keys in pipelined batches. It talks to
+	// Redis and so runs off the UI goroutine.
+	fetchRows := func(keys []string) ([]KeyData, error) {
+		metas, err := redis.GetKeysMeta(keys)
 		if err != nil {
-			table.SetCell(1, 0,
-				tview.NewTableCell(fmt.Sprintf("Error: %v", err)).
-					SetTextColor(tcell.ColorRed))
+			return nil, err
+		}
+
+		cluster := redis.IsCluster()
+		rows := make([]KeyData, 0, len(metas))
+		for _, meta := range metas {
+			// Skip keys that expired or were deleted since the scan
+			if meta.Missing() {
+				continue
+			}
+			rows = append(rows, keyDataFromMeta(redis, meta, cluster))
+		}
+		return rows, nil
+	}
+
+	// Only one page load or row refresh talks to Redis at a time
+	var busy sync.Mutex
+
+	// Auto-refresh settings, shared with the refresh goroutine. An interval
+	// of zero means the table only refreshes on demand.
+	var refreshMu sync.Mutex
+	refreshInterval, refreshPaused := defaultRefreshInterval, false
+	refreshWake := make(chan struct{}, 1)
+	refreshSettings := func() (time.Duration, bool) {
+		refreshMu.Lock()
+		defer refreshMu.Unlock()
+		return refreshInterval, refreshPaused
+	}
+	setRefresh := func(interval time.Duration, paused bool) {
+		refreshMu.Lock()
+		refreshInterval, refreshPaused = interval, paused
+		refreshMu.Unlock()
+		// Wake the refresh goroutine so the change applies right away
+		select {
+		case refreshWake <- struct{}{}:
+		default:
+		}
+	}
+
+	// refreshInfo shows the auto-refresh mode and when the table was last
+	// refreshed, next to the connection status
+	refreshInfo := tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignRight)
+	var lastRefreshed time.Time
+	updateRefreshInfo := func() {
+		interval, paused := refreshSettings()
+		mode := fmt.Sprintf("[green]auto %s[white]", interval)
+		if paused {
+			mode = "[yellow]paused[white]"
+		} else if interval <= 0 {
+			mode = "[gray]manual[white]"
+		}
+		last := "never"
+		if !lastRefreshed.IsZero() {
+			last = lastRefreshed.Format("15:04:05")
+		}
+		refreshInfo.SetText(fmt.Sprintf("%s · refreshed %s", mode, last))
+	}
+
+	// restoringSelection suppresses the details pane while the selection is
+	// moved back onto the key that was already selected
+	restoringSelection := false
+
+	// keepSelection runs mutate, which changes the rows, and then selects
+	// the previously selected key again, wherever it moved to. If the key is
+	// gone the selection stays on the same row.
+	keepSelection := func(mutate func()) {
+		row, _ := table.GetSelection()
+		selected := ""
+		if row > 0 && row-1 < len(content.rows) {
+			selected = content.rows[row-1].key
+		}
+
+		mutate()
+
+		target := row
+		if i := content.indexOf(selected); i >= 0 {
+			target = i + 1
+		} else if target > len(content.rows) {
+			target = len(content.rows)
+		}
+		if target < 1 {
+			target = 1
+		}
+		restoringSelection = selected != "" && target-1 < len(content.rows) && content.rows[target-1].key == selected
+		table.Select(target, 0)
+		restoringSelection = false
+	}
+
+	// loadPage runs move, which reads a page from the pager, and shows the
+	// result. It returns immediately; the table updates when the page is in.
+	// With fresh set the view starts at the top, otherwise the selected key
+	// and the scroll position are kept.
+	loadPage := func(move func(p *utils.KeyPager) ([]string, error), fresh bool) {
+		if !redis.IsConnected() {
 			return
 		}
+		p := currentPager()
+		sortBy, sortDesc := content.sortBy, content.sortDesc
+		var previous []KeyData
+		if !fresh {
+			previous = append(previous, content.rows...)
+		}
+		go func() {
+			busy.Lock()
+			defer busy.Unlock()
 
-		allData := make([]KeyData, 0, len(keys))
+			keys, err := move(p)
+			var rows []KeyData
+			if err == nil {
+				rows, err = fetchRows(keys)
+			}
 
-		for _, key := range keys {
-			value, err := redis.GetValue(key)
-			if err != nil {
-				value = fmt.Sprintf("Error: %v", err)
+			// Keys that left the page are only flagged once the server
+			// confirms they are gone
+			missing := make(map[string]bool)
+			if dropped := droppedKeys(previous, rows); err == nil && len(dropped) > 0 {
+				if metas, metaErr := redis.GetKeysMeta(dropped); metaErr == nil {
+					for _, meta := range metas {
+						if meta.Missing() {
+							missing[meta.Key] = true
+						}
+					}
+				}
 			}
 
-			ttl, err := redis.GetTTL(key)
-			ttlStr := "-1"
-			if err == nil {
-				if ttl < 0 {
-					ttlStr = "-1"
+			sortKeyData(rows, sortBy, sortDesc)
+
+			number, prev, next := p.Page(), p.HasPrev(), p.HasNext()
+			app.QueueUpdateDraw(func() {
+				page, hasPrev, hasNext = number, prev, next
+				if fresh {
+					content.cluster = redis.IsCluster()
+					content.rows, content.err = rows, err
+					table.Select(1, 0)
+					table.SetOffset(0, 0)
 				} else {
-					ttlStr = fmt.Sprintf("%.0f s", ttl.Seconds())
+					keepSelection(func() {
+						content.cluster = redis.IsCluster()
+						if err == nil {
+							rows = flagVanished(content.rows, rows, missing)
+						}
+						content.rows, content.err = rows, err
+					})
+				}
+				if err == nil {
+					lastRefreshed = time.Now()
 				}
+				updateRefreshInfo()
+				updateFooter()
+			})
+		}()
+	}
+
+	// reload rescans the current page in place, restart goes back to the
+	// first page
+	reload := func() {
+		loadPage(func(p *utils.KeyPager) ([]string, error) { return p.Load(redis.Context()) }, false)
+	}
+	restart := func() {
+		pager = nil
+		loadPage(func(p *utils.KeyPager) ([]string, error) { return p.Load(redis.Context()) }, true)
+	}
+
+	// visibleKeys returns the keys of the rows currently on screen. It must
+	// run on the UI goroutine.
+	visibleKeys := func() []string {
+		if treeShown {
+			return nil
+		}
+		rowOffset, _ := table.GetOffset()
+		_, _, _, height := table.GetInnerRect()
+		// With borders every row takes two lines
+		visible := height/2 + 1
+
+		start := rowOffset
+		end := rowOffset + visible
+		if start > len(content.rows) {
+			start = len(content.rows)
+		}
+		if end > len(content.rows) {
+			end = len(content.rows)
+		}
+
+		keys := make([]string, 0, end-start)
+		for _, data := range content.rows[start:end] {
+			// Vanished keys are kept on screen as they were
+			if data.gone == "" {
+				keys = append(keys, data.key)
 			}
+		}
+		return keys
+	}
 
-			memoryCmd := fmt.Sprintf("memory usage %s", key)
-			memoryResult, err := redis.ExecuteCommand(memoryCmd)
-			var memoryBytes int64 = 0
-			if err == nil {
-				if memInt, err := strconv.ParseInt(fmt.Sprintf("%v", memoryResult), 10, 64); err == nil {
-					memoryBytes = memInt
+	// refreshVisible re-reads the TTL, memory and preview of the rows on
+	// screen only, dropping rows whose key disappeared. The page itself is
+	// not rescanned.
+	refreshVisible := func() {
+		if !busy.TryLock() {
+			return
+		}
+		defer busy.Unlock()
+
+		keysCh := make(chan []string, 1)
+		app.QueueUpdate(func() {
+			keysCh <- visibleKeys()
+		})
+		keys := <-keysCh
+		if len(keys) == 0 {
+			return
+		}
+
+		metas, err := redis.GetKeysMeta(keys)
+		if err != nil {
+			return
+		}
+
+		// Rows are built here, the UI only swaps them in
+		cluster := redis.IsCluster()
+		rows := make([]KeyData, len(metas))
+		for i, meta := range metas {
+			if !meta.Missing() {
+				rows[i] = keyDataFromMeta(redis, meta, cluster)
+			}
+		}
+		app.QueueUpdateDraw(func() {
+			keepSelection(func() {
+				for j, meta := range metas {
+					i := content.indexOf(meta.Key)
+					if i < 0 {
+						continue
+					}
+					if meta.Missing() {
+						content.rows[i].gone = vanishedReason(content.rows[i])
+						continue
+					}
+					pinned := content.rows[i].pinned
+					content.rows[i] = rows[j]
+					content.rows[i].pinned = pinned
 				}
+			})
+			lastRefreshed = time.Now()
+			updateRefreshInfo()
+			updateFooter()
+		})
+	}
+
+	// showKeyDetails writes the full value and TTL of key to the display
+	// pane. The reads run in the background with a deadline, and only the
+	// most recent selection is shown.
+	detailsGeneration := 0
+	showKeyDetails := func(key string) {
+		detailsGeneration++
+		generation := detailsGeneration
+		go func() {
+			ctx, cancel := context.WithTimeout(redis.Context(), keyDetailsTimeout)
+			defer cancel()
+			conn := redis.WithContext(ctx)
+
+			fullValue, err := conn.GetTypedValue(key)
+			ttl, _ := conn.GetTTL(key)
+
+			// Create a more detailed and formatted display
+			displayText := fmt.Sprintf("[yellow]Key Details:[white]\n")
+			displayText += fmt.Sprintf("Full Key: [green]%s[white]\n", key)
+			if node, err := conn.KeyNode(key); err == nil && node != "" {
+				displayText += fmt.Sprintf("Node: [green]%s[white]\n", node)
+			}
+			displayText += "\n"
+
+			if err != nil {
+				displayText += fmt.Sprintf("[yellow]Value:[white]\n")
+				displayText += fmt.Sprintf("[red]Error retrieving value:[white] %v\n", err)
+			} else {
+				// Show full value formatted for its type
+				displayText += fmt.Sprintf("[yellow]Type:[white] %s\n\n", valueTypeLabel(fullValue))
+				displayText += fmt.Sprintf("[yellow]Value:[white]\n")
+				displayText += fmt.Sprintf("%s\n", FormatValue(fullValue))
 			}
 
-			// Truncate value for table display
-			const maxValueLength = 30
-			displayValue := value
-			if len(displayValue) > maxValueLength {
-				displayValue = displayValue[:maxValueLength] + "..."
+			if ttl >= 0 {
+				displayText += fmt.Sprintf("[yellow]TTL:[white] %s (%d ms)\n", formatTTL(ttl), ttl.Milliseconds())
+			} else {
+				displayText += "[yellow]TTL:[white] Persistent (no expiration)\n"
 			}
 
-			allData = append(allData, KeyData{
-				key:    key,
-				value:  value,
-				ttl:    ttlStr,
-				memory: memoryBytes,
+			app.QueueUpdateDraw(func() {
+				if generation == detailsGeneration {
+					kvDisplay.SetText(displayText)
+				}
 			})
+		}()
+	}
+
+	// Modify selection changed function to handle long keys
+	table.SetSelectionChangedFunc(func(row, column int) {
+		if restoringSelection {
+			return
+		}
+		if row > 0 && row-1 < len(content.rows) {
+			showKeyDetails(content.rows[row-1].key)
 		}
+	})
 
-		sort.Slice(allData, func(i, j int) bool {
-			return allData[i].memory < allData[j].memory
-		})
+	var treeView *tview.Flex
+	var reloadTree func()
+	treeLoaded := false
+	showTable := func() {
+		treeShown = false
+		mainFlex.RemoveItem(treeView)
+		mainFlex.AddItem(tableView, 0, 10, true)
+		app.SetFocus(table)
+	}
+	showTree := func() {
+		treeShown = true
+		mainFlex.RemoveItem(tableView)
+		mainFlex.AddItem(treeView, 0, 10, true)
+		app.SetFocus(treeView)
+		// The root is read on first use only, r in the tree reloads it
+		if !treeLoaded {
+			treeLoaded = true
+			reloadTree()
+		}
+	}
+	treeView, reloadTree = NamespaceView(app, redis, views.runner, showKeyDetails, showTable)
 
-		for i, data := range allData {
-			rowIndex := i + 1
-			table.SetCell(rowIndex, 0,
-				tview.NewTableCell(data.key).
-					SetMaxWidth(15).
-					SetExpansion(1))
+	// setSort re-sorts the loaded page, keeping the selected key selected
+	setSort := func(by int, desc bool) {
+		selected := ""
+		if row, _ := table.GetSelection(); row > 0 && row-1 < len(content.rows) {
+			selected = content.rows[row-1].key
+		}
+		content.sortBy, content.sortDesc = by, desc
+		sortKeyData(content.rows, by, desc)
+		if i := content.indexOf(selected); i >= 0 {
+			table.Select(i+1, 0)
+		}
+		updateFooter()
+	}
 
-			table.SetCell(rowIndex, 1,
-				tview.NewTableCell(data.value).
-					SetMaxWidth(4).
-					SetExpansion(2))
+	// Filter prompt, shown in place of the footer. Tab switches between a
+	// glob and a regular expression.
+	filterInput := tview.NewInputField()
+	filterInputRegex := false
+	setFilterLabel := func() {
+		if filterInputRegex {
+			filterInput.SetLabel("Filter (regex, Tab for glob): ")
+		} else {
+			filterInput.SetLabel("Filter (glob, Tab for regex): ")
+		}
+	}
+	filterInput.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab {
+			filterInputRegex = !filterInputRegex
+			setFilterLabel()
+			return nil
+		}
+		return event
+	})
+	filterInput.SetDoneFunc(func(key tcell.Key) {
+		tableView.RemoveItem(filterInput)
+		tableView.AddItem(footer, 1, 0, false)
+		app.SetFocus(table)
+		if key != tcell.KeyEnter {
+			return
+		}
+
+		text := filterInput.GetText()
+		var match func(key string) bool
+		if text != "" && filterInputRegex {
+			re, err := regexp.Compile(text)
+			if err != nil {
+				footer.SetText(fmt.Sprintf("[red]Invalid regex: %v[white]", err))
+				return
+			}
+			match = re.MatchString
+		}
+
+		// A new filter starts over at the first page
+		filter, filterRegex, filterMatch = text, filterInputRegex && text != "", match
+		restart()
+	})
+
+	// One-line prompt shown in place of the footer; done gets the text
+	// when the prompt is confirmed with Enter
+	prompt := tview.NewInputField()
+	showPrompt := func(label, text string, done func(text string)) {
+		prompt.SetLabel(label).SetText(text)
+		prompt.SetDoneFunc(func(key tcell.Key) {
+			tableView.RemoveItem(prompt)
+			tableView.AddItem(footer, 1, 0, false)
+			app.SetFocus(table)
+			if key == tcell.KeyEnter {
+				done(prompt.GetText())
+			}
+		})
+		tableView.RemoveItem(footer)
+		tableView.AddItem(prompt, 1, 0, false)
+		app.SetFocus(prompt)
+	}
 
-			table.SetCell(rowIndex, 2,
-				tview.NewTableCell(data.ttl).
-					SetMaxWidth(10).
-					SetExpansion(1))
+	// jumpToKey selects target, pinning it to the top of the page when the
+	// page does not contain it
+	jumpToKey := func(target string) {
+		if target == "" {
+			return
+		}
 
-			table.SetCell(rowIndex, 3,
-				tview.NewTableCell(fmt.Sprintf("%d B", data.memory)).
-					SetMaxWidth(10).
-					SetExpansion(1))
+		// Keys on the current page are simply selected
+		if i := content.indexOf(target); i >= 0 {
+			table.Select(i+1, 0)
+			return
 		}
+
+		// Otherwise the key is pinned to the top of the page, as SCAN order
+		// gives no way to tell which page it would be on
+		go func() {
+			metas, err := redis.GetKeysMeta([]string{target})
+			found := err == nil && len(metas) > 0 && !metas[0].Missing()
+			var data KeyData
+			if found {
+				data = keyDataFromMeta(redis, metas[0], redis.IsCluster())
+			}
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					footer.SetText(fmt.Sprintf("[red]Error: %v[white]", err))
+					return
+				}
+				if !found {
+					footer.SetText(fmt.Sprintf("[red]Key '%s' does not exist[white]", tview.Escape(target)))
+					return
+				}
+				data.pinned = true
+				content.rows = append([]KeyData{data}, content.rows...)
+				table.SetOffset(0, 0)
+				table.Select(1, 0)
+				updateFooter()
+			})
+		}()
 	}
 
-	// Modify selection changed function to handle long keys
-	table.SetSelectionChangedFunc(func(row, column int) {
-		if row > 0 && row < table.GetRowCount() {
-			keyCell := table.GetCell(row, 0)
-			if keyCell != nil {
-				key := keyCell.Text
-				fullValue, err := redis.GetValue(key)
-				ttl, _ := redis.GetTTL(key)
+	// Row actions. Each one runs through the operation runner, so it can be
+	// cancelled with Esc or Ctrl+C, and is logged to the Logs pane.
+	runner := views.runner
+	logAction := func(format string, args ...interface{}) {
+		logDisplay.Write([]byte(fmt.Sprintf(format, args...) + "\n"))
+	}
 
-				// Create a more detailed and formatted display
-				displayText := fmt.Sprintf("[yellow]Key Details:[white]\n")
-				displayText += fmt.Sprintf("Full Key: [green]%s[white]\n\n", key)
+	// selectedKey returns the key of the selected row
+	selectedKey := func() (string, bool) {
+		row, _ := table.GetSelection()
+		if row <= 0 || row-1 >= len(content.rows) {
+			return "", false
+		}
+		return content.rows[row-1].key, true
+	}
 
-				displayText += fmt.Sprintf("[yellow]Value:[white]\n")
+	deleteKey := func(key string) {
+		pendingDelete = key
+		footer.SetText(fmt.Sprintf("[red]Delete key '%s'? (y/n)[white]", tview.Escape(key)))
+	}
+	confirmDelete := func(key string) {
+		runner.Start("delete", func(conn *utils.RedisConnection) func() {
+			result, err := conn.ExecuteArgs([]string{"del", key})
+			return func() {
+				if isCancelled(err) {
+					return
+				}
 				if err != nil {
-					displayText += fmt.Sprintf("[red]Error retrieving value:[white] %v\n", err)
+					logAction("[red]Delete Error:[white] %v", err)
+				} else if deleted, _ := result.(int64); deleted == 0 {
+					logAction("[yellow]Key '%s' no longer exists[white]", tview.Escape(key))
 				} else {
-					// Show full value with better formatting
-					displayText += fmt.Sprintf("%s\n\n", fullValue)
+					logAction("[green]Deleted key '%s'[white]", tview.Escape(key))
 				}
+				reload()
+			}
+		})
+	}
 
-				if ttl >= 0 {
-					displayText += fmt.Sprintf("[yellow]TTL:[white] %.0f seconds\n", ttl.Seconds())
-				} else {
-					displayText += "[yellow]TTL:[white] Persistent (no expiration)\n"
+	renameKey := func(key string) {
+		showPrompt("Rename to: ", key, func(newKey string) {
+			if newKey == "" || newKey == key {
+				return
+			}
+			runner.Start("rename", func(conn *utils.RedisConnection) func() {
+				renamed, err := conn.RenameNX(key, newKey)
+				return func() {
+					if isCancelled(err) {
+						return
+					} else if err != nil {
+						logAction("[red]Rename Error:[white] %v", err)
+						return
+					} else if !renamed {
+						logAction("[red]Rename Error:[white] key '%s' already exists", tview.Escape(newKey))
+						return
+					}
+					logAction("[green]Renamed '%s' to '%s'[white]", tview.Escape(key), tview.Escape(newKey))
+					reload()
 				}
+			})
+		})
+	}
 
-				kvDisplay.SetText(displayText)
+	persistKey := func(key string) {
+		runner.Start("persist", func(conn *utils.RedisConnection) func() {
+			err := conn.Expire(key, 0)
+			return func() {
+				if isCancelled(err) {
+					return
+				} else if err != nil {
+					logAction("[red]Persist Error:[white] %v", err)
+					return
+				}
+				logAction("[green]Removed the TTL of '%s'[white]", tview.Escape(key))
+				reload()
 			}
+		})
+	}
+
+	setTTL := func(key string) {
+		showPrompt("TTL (seconds or 1h30m, 0 to persist): ", "", func(text string) {
+			text = strings.TrimSpace(text)
+			ttl, err := time.ParseDuration(text)
+			if err != nil {
+				seconds, convErr := strconv.ParseInt(text, 10, 64)
+				if convErr != nil || seconds < 0 {
+					logAction("[red]TTL Error:[white] invalid TTL '%s'", tview.Escape(text))
+					return
+				}
+				ttl = time.Duration(seconds) * time.Second
+			}
+			if ttl <= 0 {
+				persistKey(key)
+				return
+			}
+			runner.Start("set TTL", func(conn *utils.RedisConnection) func() {
+				err := conn.Expire(key, ttl)
+				return func() {
+					if isCancelled(err) {
+						return
+					} else if err != nil {
+						logAction("[red]TTL Error:[white] %v", err)
+						return
+					}
+					logAction("[green]Set the TTL of '%s' to %s[white]", tview.Escape(key), ttl)
+					reload()
+				}
+			})
+		})
+	}
+
+	duplicateKey := func(key string) {
+		showPrompt("Duplicate as: ", key+"_copy", func(newKey string) {
+			if newKey == "" || newKey == key {
+				return
+			}
+			runner.Start("duplicate", func(conn *utils.RedisConnection) func() {
+				err := conn.Duplicate(key, newKey)
+				return func() {
+					if isCancelled(err) {
+						return
+					} else if err != nil {
+						logAction("[red]Duplicate Error:[white] %v", err)
+						return
+					}
+					logAction("[green]Duplicated '%s' as '%s'[white]", tview.Escape(key), tview.Escape(newKey))
+					reload()
+				}
+			})
+		})
+	}
+
+	copyKeyName := func(key string) {
+		via, err := copyToClipboard(key)
+		if err != nil {
+			logAction("[red]Copy Error:[white] %v", err)
+			return
+		}
+		logAction("[green]Copied key name '%s' to the clipboard via %s[white]", tview.Escape(key), via)
+	}
+
+	keyActions := []struct {
+		name     string
+		shortcut rune
+		run      func(key string)
+	}{
+		{"View value", 'v', views.View},
+		{"Open editor", 'o', views.Open},
+		{"Delete", 'x', deleteKey},
+		{"Rename", 'm', renameKey},
+		{"Set TTL", 'e', setTTL},
+		{"Persist (remove TTL)", 'P', persistKey},
+		{"Duplicate", 'D', duplicateKey},
+		{"Copy key name", 'c', copyKeyName},
+	}
+
+	// The context menu floats over the table
+	tablePages := tview.NewPages()
+	showMenu := func() {
+		key, ok := selectedKey()
+		if !ok {
+			return
+		}
+
+		menu := tview.NewList()
+		menu.SetBorder(true).SetTitle(fmt.Sprintf(" %s ", tview.Escape(key)))
+		closeMenu := func() {
+			tablePages.RemovePage("menu")
+			app.SetFocus(table)
+		}
+		for _, action := range keyActions {
+			run := action.run
+			menu.AddItem(action.name, "", action.shortcut, func() {
+				closeMenu()
+				run(key)
+			})
+		}
+		menu.SetDoneFunc(closeMenu)
+
+		width, height := 28, 2*len(keyActions)+2
+		tablePages.AddPage("menu", tview.NewFlex().
+			AddItem(nil, 0, 1, false).
+			AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+				AddItem(nil, 0, 1, false).
+				AddItem(menu, height, 0, true).
+				AddItem(nil, 0, 1, false), width, 0, true).
+			AddItem(nil, 0, 1, false), true, true)
+		app.SetFocus(menu)
+	}
+
+	table.SetSelectedFunc(func(row, column int) {
+		showMenu()
+	})
+
+	// Clicking a header sorts by that column, clicking it again reverses.
+	// Right clicking a row opens its context menu.
+	table.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		if action == tview.MouseRightClick {
+			if row, _ := table.CellAt(event.Position()); row > 0 && row-1 < len(content.rows) {
+				app.SetFocus(table)
+				table.Select(row, 0)
+				showMenu()
+				return action, nil
+			}
+			return action, event
+		}
+		if action != tview.MouseLeftClick {
+			return action, event
+		}
+		row, column := table.CellAt(event.Position())
+		if row != 0 {
+			return action, event
+		}
+		if by := content.columnSort(column); by >= 0 {
+			setSort(by, by == content.sortBy && !content.sortDesc)
 		}
+		app.SetFocus(table)
+		return action, nil
+	})
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if pendingDelete != "" {
+			key := pendingDelete
+			pendingDelete = ""
+			if event.Rune() == 'y' {
+				confirmDelete(key)
+			} else {
+				logAction("[yellow]Delete of '%s' cancelled[white]", tview.Escape(key))
+				updateFooter()
+			}
+			return nil
+		}
+
+		if event.Key() == tcell.KeyDelete {
+			if key, ok := selectedKey(); ok {
+				deleteKey(key)
+			}
+			return nil
+		}
+		for _, action := range keyActions {
+			if event.Rune() == action.shortcut {
+				if key, ok := selectedKey(); ok {
+					action.run(key)
+				}
+				return nil
+			}
+		}
+
+		switch event.Rune() {
+		case 'n':
+			if hasNext {
+				loadPage(func(p *utils.KeyPager) ([]string, error) { return p.Next(redis.Context()) }, true)
+			}
+			return nil
+		case 'p':
+			if hasPrev {
+				loadPage(func(p *utils.KeyPager) ([]string, error) { return p.Prev(redis.Context()) }, true)
+			}
+			return nil
+		case 'r':
+			reload()
+			return nil
+		case 'a':
+			interval, paused := refreshSettings()
+			setRefresh(interval, !paused)
+			updateRefreshInfo()
+			return nil
+		case 'i':
+			interval, _ := refreshSettings()
+			current := interval.String()
+			if interval <= 0 {
+				current = "manual"
+			}
+			showPrompt("Refresh every (e.g. 5s, manual): ", current, func(text string) {
+				interval, err := parseRefreshInterval(text)
+				if err != nil {
+					footer.SetText(fmt.Sprintf("[red]%v[white]", err))
+					return
+				}
+				_, paused := refreshSettings()
+				setRefresh(interval, paused)
+				updateRefreshInfo()
+			})
+			return nil
+		case 't':
+			showTree()
+			return nil
+		case 's':
+			// Cycle through the sort fields
+			setSort((content.sortBy+1)%len(sortNames), content.sortDesc)
+			return nil
+		case 'S':
+			setSort(content.sortBy, !content.sortDesc)
+			return nil
+		case '/':
+			filterInput.SetText(filter)
+			filterInputRegex = filterRegex
+			setFilterLabel()
+			tableView.RemoveItem(footer)
+			tableView.AddItem(filterInput, 1, 0, false)
+			app.SetFocus(filterInput)
+			return nil
+		case 'f':
+			showPrompt("Find key: ", "", jumpToKey)
+			return nil
+		}
+		return event
+	})
+
+	// Connection health indicator shown above the table
+	status := tview.NewTextView().SetDynamicColors(true)
+	updateStatus := func(state utils.ConnectionState) {
+		switch state {
+		case utils.StateConnected:
+			status.SetText(fmt.Sprintf("[green]● connected[white] db %d", redis.DB()))
+		case utils.StateReconnecting:
+			status.SetText("[yellow]● reconnecting...[white] refresh paused")
+		case utils.StateDown:
+			status.SetText("[red]● down[white] retrying, refresh paused")
+		default:
+			status.SetText("[gray]● disconnected[white]")
+		}
+	}
+	updateStatus(redis.State())
+	redis.OnStateChange(func(state utils.ConnectionState) {
+		app.QueueUpdateDraw(func() {
+			updateStatus(state)
+			// A database switch or a new connection starts over at page one
+			if state == utils.StateConnected && pager != nil &&
+				(pagerDB != redis.DB() || pagerCluster != redis.IsCluster()) {
+				restart()
+			}
+		})
 	})
 
 	go func() {
 		for {
-			app.QueueUpdateDraw(refreshTableData)
-			time.Sleep(1 * time.Second)
+			interval, paused := refreshSettings()
+			if paused || interval <= 0 {
+				// Sleep until auto-refresh is turned back on
+				<-refreshWake
+				continue
+			}
+
+			select {
+			case <-time.After(interval):
+			case <-refreshWake:
+				// The settings changed, start over with the new interval
+				continue
+			}
+
+			// Keep the last rows on screen while the server is unreachable
+			if redis.State() == utils.StateConnected {
+				refreshVisible()
+			}
 		}
 	}()
 
-	refreshTableData()
+	// Redraw twice a second while any row counts down, without asking Redis
+	go func() {
+		for {
+			time.Sleep(500 * time.Millisecond)
+			countingDown := make(chan bool, 1)
+			app.QueueUpdate(func() {
+				for _, data := range content.rows {
+					if !treeShown && !data.expires.IsZero() && data.gone == "" {
+						countingDown <- true
+						return
+					}
+				}
+				countingDown <- false
+			})
+			if <-countingDown {
+				app.Draw()
+			}
+		}
+	}()
+
+	updateRefreshInfo()
+	restart()
+
+	tablePages.AddPage("table", table, true, true)
+	tableView.AddItem(tablePages, 0, 1, true)
+	tableView.AddItem(footer, 1, 0, false)
+
+	statusBar := tview.NewFlex().
+		AddItem(status, 0, 1, false).
+		AddItem(refreshInfo, 0, 1, false)
 
-	mainFlex.AddItem(table, 0, 10, true)
+	mainFlex.AddItem(statusBar, 1, 0, false)
+	mainFlex.AddItem(tableView, 0, 10, true)
 
 	return mainFlex
 }
@@ -1,11 +1,11 @@
 package windows
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
-	"time"
 
 	"github.com/Amrit02102004/RediCLI/utils"
 	"github.com/gdamore/tcell/v2"
@@ -27,6 +27,12 @@ var enhancedCommandSuggestions = []EnhancedCommandSuggestion{
 	{"key filter set", "Open key set form with TTL in milliseconds", "Advanced"},
 	{"key filter update", "Open key update form with KEEPTTL option", "Advanced"},
 	{"get", "Retrieve the value of a key", "Basic"},
+	{"view", "Open a key in the value viewer (JSON tree, hex, decoded)", "Basic"},
+	{"open", "Open the editor of a hash, list, set or sorted set, or browse a stream", "Basic"},
+	{"edit", "Edit a key's value in $EDITOR (hashes and lists as JSON)", "Basic"},
+	{"pubsub", "Open the pub/sub console (subscribe, publish, active channels)", "Advanced"},
+	{"subscribe", "Subscribe to channels in the pub/sub console", "Advanced"},
+	{"psubscribe", "Subscribe to channel patterns in the pub/sub console", "Advanced"},
 	{"set", "Set the string value of a key", "Basic"},
 	{"del", "Delete a key", "Basic"},
 	{"keys", "Find all keys matching a pattern", "Basic"},
@@ -43,12 +49,13 @@ var enhancedCommandSuggestions = []EnhancedCommandSuggestion{
 	{"connect", "Connect to a saved Redis connection by name", "Connection Management"},
 	{"del connection", "Delete a specific saved Redis connection", "Connection Management"},
 	{"del all connections", "Delete all saved Redis connections", "Connection Management"},
+	{"use db", "Switch the logical database (use db <n>, or pick from a list)", "Connection Management"},
 	{"select from", "Query Redis keys with conditions (e.g., TTL, value pattern)", "Query"},
 	{"update", "Update Redis keys matching conditions (value/key/ttl)", "Query"},
 	{"del from", "Delete Redis keys matching conditions", "Query"},
 }
 
-func Win3(app *tview.Application, logDisplay *tview.TextView, redis *utils.RedisConnection) (*tview.Flex, *tview.TextView, *tview.InputField, *tview.Flex) {
+func Win3(app *tview.Application, logDisplay *tview.TextView, redis *utils.RedisConnection) (*tview.Flex, *tview.TextView, *tview.InputField, *tview.Flex, *KeyViews) {
 	cmdFlex := tview.NewFlex().SetDirection(tview.FlexRow)
 
 	// Create suggestion display
@@ -88,9 +95,42 @@ func Win3(app *tview.Application, logDisplay *tview.TextView, redis *utils.Redis
 			suggestionDisplay.SetText("")
 		}
 	})
+	// Slow commands, queries, exports and key reads run in the background
+	// and can be cancelled with Esc or Ctrl+C, from any pane
+	runner := NewOperationRunner(app, logDisplay, redis)
+	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if (event.Key() == tcell.KeyCtrlC || event.Key() == tcell.KeyEsc) && runner.Cancel() {
+			// Only quit on Ctrl+C, or close a view on Esc, when nothing is running
+			return nil
+		}
+		return event
+	})
+
 	commandHistory := []string{}
 	currentHistoryIndex := -1
 
+	pane := NewDisplayPane(app, cmdFlex, formContainer, kvDisplay, suggestionDisplay, cmdInput)
+	views := NewKeyViews(app, redis, logDisplay, pane, runner)
+	pubsub := NewPubSubConsole(app, runner, logDisplay, pane)
+	// connectSaved connects to the saved connection name, asking for the
+	// passwords it was saved without, and calls then once connected
+	connectSaved := func(name string, then func(config ConnectionConfig)) {
+		config, err := FindConnectionByName(name)
+		if err != nil {
+			logDisplay.Write([]byte(fmt.Sprintf("[red]Connection Error:[white] %v\n", err)))
+			return
+		}
+		askPasswords(pane, *config, func(config ConnectionConfig) {
+			if err := redis.ConnectWithOptions(config.ConnectOptions()); err != nil {
+				logDisplay.Write([]byte(connectionErrorMessage(err)))
+				return
+			}
+			then(config)
+		})
+	}
+	// lastKey is the key most recently shown by get, used by a bare view or open
+	lastKey := ""
+
 	// Modify the SetInputCapture function in Win3
 	cmdInput.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Key() {
@@ -164,85 +204,149 @@ func Win3(app *tview.Application, logDisplay *tview.TextView, redis *utils.Redis
 		// Assuming you have a `redis` package handling Redis operations
 
 		if strings.HasPrefix(cmd, "get ") {
-			// Extract the key name
-			keyName := strings.TrimSpace(strings.TrimPrefix(cmd, "get"))
-
-			// Check if key exists
-			exists, err := redis.KeyExists(keyName)
-			if err != nil {
-				logDisplay.Write([]byte(fmt.Sprintf("[red]Error checking key:[white] %v\n", err)))
+			// Extract the key name, which may be quoted
+			args, err := utils.SplitArgs(cmd)
+			if err != nil || len(args) != 2 {
+				logDisplay.Write([]byte("[red]Usage:[white] get <key>\n"))
 				cmdInput.SetText("")
 				return
 			}
+			keyName := args[1]
+			cmdInput.SetText("")
+
+			runner.Start("get", func(conn *utils.RedisConnection) func() {
+				// Check if key exists
+				exists, err := conn.KeyExists(keyName)
+				if err != nil {
+					return func() {
+						if !isCancelled(err) {
+							logDisplay.Write([]byte(fmt.Sprintf("[red]Error checking key:[white] %v\n", err)))
+						}
+					}
+				}
 
-			if !exists {
-				logDisplay.Write([]byte(fmt.Sprintf("[yellow]Key '%s' does not exist[white]\n", keyName)))
-				kvDisplay.Clear()
-				DisplayWelcomeMessage(kvDisplay)
+				if !exists {
+					return func() {
+						logDisplay.Write([]byte(fmt.Sprintf("[yellow]Key '%s' does not exist[white]\n", keyName)))
+						kvDisplay.Clear()
+						DisplayWelcomeMessage(kvDisplay)
+					}
+				}
+
+				// Get the key value, read according to its type
+				value, err := conn.GetTypedValue(keyName)
+				if err != nil {
+					return func() {
+						if !isCancelled(err) {
+							logDisplay.Write([]byte(fmt.Sprintf("[red]Error getting value:[white] %v\n", err)))
+						}
+					}
+				}
+
+				// Get the TTL
+				ttl, err := conn.GetTTL(keyName)
+				if err != nil {
+					return func() {
+						if !isCancelled(err) {
+							logDisplay.Write([]byte(fmt.Sprintf("[red]Error getting TTL:[white] %v\n", err)))
+						}
+					}
+				}
+
+				return func() {
+					// Log key existence
+					logDisplay.Write([]byte(fmt.Sprintf("[green]Key '%s' found[white]\n", keyName)))
+					lastKey = keyName
+
+					// Clear previous display and show key details
+					kvDisplay.Clear()
+
+					// Format TTL display
+					var ttlDisplay string
+					switch {
+					case ttl == -1:
+						ttlDisplay = "No expiration"
+					case ttl == -2:
+						ttlDisplay = "Key does not exist"
+					default:
+						ttlDisplay = formatTTL(ttl) + " remaining"
+					}
+
+					// Display key details
+					kvDisplay.SetText(fmt.Sprintf(
+						"[green]Key Information:[white]\n\n"+
+							"[yellow]Key Name:[white] %s\n\n"+
+							"[yellow]Type:[white] %s\n\n"+
+							"[yellow]Value:[white]\n%s\n"+
+							"[yellow]Time to Live (TTL):[white] %s\n\n"+
+							"[gray]Type 'view' to open it as a JSON tree, hex dump or decoded payload%s[white]",
+						keyName, valueTypeLabel(value), FormatValue(value), ttlDisplay, openHint(value.Type),
+					)).SetTextAlign(tview.AlignLeft)
+				}
+			})
+			return
+		}
+
+		if args, err := utils.SplitArgs(cmd); err == nil && len(args) > 0 && (args[0] == "view" || args[0] == "open") {
+			if len(args) > 2 {
+				logDisplay.Write([]byte(fmt.Sprintf("[red]Usage:[white] %s [key[]\n", args[0])))
 				cmdInput.SetText("")
 				return
 			}
-
-			// Get the key value
-			value, err := redis.GetValue(keyName)
-			if err != nil {
-				logDisplay.Write([]byte(fmt.Sprintf("[red]Error getting value:[white] %v\n", err)))
+			keyName := lastKey
+			if len(args) == 2 {
+				keyName = args[1]
+			}
+			if keyName == "" {
+				logDisplay.Write([]byte(fmt.Sprintf("[red]Usage:[white] %s <key> (or get a key first)\n", args[0])))
 				cmdInput.SetText("")
 				return
 			}
 
-			// Log key existence
-			logDisplay.Write([]byte(fmt.Sprintf("[green]Key '%s' found[white]\n", keyName)))
-
-			// Try pretty-printing JSON
-			var prettyJSON string
-			var formattedJSON map[string]interface{}
-
-			if err := json.Unmarshal([]byte(value), &formattedJSON); err == nil {
-				// Successfully parsed JSON, format it
-				if prettyJSONBytes, err := json.MarshalIndent(formattedJSON, "", "  "); err == nil {
-					prettyJSON = string(prettyJSONBytes)
-				} else {
-					// If marshalling fails, fall back to raw value
-					prettyJSON = value
-				}
+			lastKey = keyName
+			cmdInput.SetText("")
+			if args[0] == "view" {
+				views.View(keyName)
 			} else {
-				// If unmarshalling fails, fall back to raw value
-				prettyJSON = value
+				views.Open(keyName)
 			}
+			return
+		}
 
-			// Get the TTL
-			ttl, err := redis.GetTTL(keyName)
-			if err != nil {
-				logDisplay.Write([]byte(fmt.Sprintf("[red]Error getting TTL:[white] %v\n", err)))
+		if strings.HasPrefix(cmd, "edit ") {
+			args, err := utils.SplitArgs(cmd)
+			if err != nil || len(args) != 2 {
+				logDisplay.Write([]byte("[red]Usage:[white] edit <key>\n"))
 				cmdInput.SetText("")
 				return
 			}
+			cmdInput.SetText("")
+			EditValue(app, runner, logDisplay, args[1])
+			return
+		}
 
-			// Clear previous display and show key details
-			kvDisplay.Clear()
-
-			// Format TTL display
-			var ttlDisplay string
+		// Subscribing would leave a pooled connection in subscribed mode, the
+		// console listens on a connection of its own
+		if args, err := utils.SplitArgs(cmd); err == nil && len(args) > 0 {
+			command := strings.ToLower(args[0])
 			switch {
-			case ttl == -1:
-				ttlDisplay = "No expiration"
-			case ttl == -2:
-				ttlDisplay = "Key does not exist"
-			default:
-				ttlDisplay = fmt.Sprintf("%v remaining", ttl.Round(time.Second))
+			case command == "pubsub" && len(args) == 1:
+				cmdInput.SetText("")
+				pubsub.Show()
+				return
+			case command == "subscribe" || command == "psubscribe":
+				cmdInput.SetText("")
+				if len(args) == 1 {
+					logDisplay.Write([]byte(fmt.Sprintf("[red]Usage:[white] %s <channel> [channel ...[]\n", command)))
+					return
+				}
+				pubsub.Subscribe(args[1:], command == "psubscribe")
+				return
+			case command == "unsubscribe" || command == "punsubscribe":
+				cmdInput.SetText("")
+				pubsub.Unsubscribe(args[1:], command == "punsubscribe")
+				return
 			}
-
-			// Display key details
-			kvDisplay.SetText(fmt.Sprintf(
-				"[green]Key Information:[white]\n\n"+
-					"[yellow]Key Name:[white] %s\n\n"+
-					"[yellow]Value:[white]\n%s\n\n"+
-					"[yellow]Time to Live (TTL):[white] %s",
-				keyName, prettyJSON, ttlDisplay,
-			)).SetTextAlign(tview.AlignLeft)
-
-			return
 		}
 
 		if strings.HasPrefix(cmd, "import .") {
@@ -261,13 +365,19 @@ func Win3(app *tview.Application, logDisplay *tview.TextView, redis *utils.Redis
 		if strings.HasPrefix(cmd, "export .") {
 			// Extract the file path
 			filePath := strings.TrimSpace(strings.TrimPrefix(cmd, "export"))
-			err := ExportData(filePath, redis)
-			if err != nil {
-				logDisplay.Write([]byte(fmt.Sprintf("[red]Import Error: %v[white]\n", err)))
-			} else {
-				logDisplay.Write([]byte("[green]Data Exported successfully[white]\n"))
-			}
-			RefreshData(logDisplay, kvDisplay, redis)
+			runner.Start("export", func(conn *utils.RedisConnection) func() {
+				err := ExportData(filePath, conn)
+				return func() {
+					if isCancelled(err) {
+						logDisplay.Write([]byte("[yellow]Export cancelled, the file is incomplete[white]\n"))
+					} else if err != nil {
+						logDisplay.Write([]byte(fmt.Sprintf("[red]Export Error: %v[white]\n", err)))
+					} else {
+						logDisplay.Write([]byte("[green]Data Exported successfully[white]\n"))
+					}
+				}
+			})
+			cmdInput.SetText("")
 			return
 		}
 
@@ -300,47 +410,49 @@ func Win3(app *tview.Application, logDisplay *tview.TextView, redis *utils.Redis
 				return
 			}
 
-			// If a different connection is specified, connect to it
-			if condition.ConnectionName != "" {
-				config, err := FindConnectionByName(condition.ConnectionName)
-				if err != nil {
-					logDisplay.Write([]byte(fmt.Sprintf("[red]Connection Error:[white] %v\n", err)))
-					cmdInput.SetText("")
-					return
-				}
+			run := func() {
+				runner.Start("query", func(conn *utils.RedisConnection) func() {
+					results, err := ExecuteQuery(conn, condition)
+					if err != nil {
+						return func() {
+							if isCancelled(err) {
+								return
+							}
+							logDisplay.Write([]byte(fmt.Sprintf("[red]Query Error:[white] %v\n", err)))
+						}
+					}
 
-				err = redis.Connect(config.Host, config.Port)
-				if err != nil {
-					logDisplay.Write([]byte(fmt.Sprintf("[red]Connection Error:[white] %v\n", err)))
-					cmdInput.SetText("")
-					return
-				}
-			}
+					// Display results
+					var displayText strings.Builder
+					displayText.WriteString("[green]Query Results:[white]\n\n")
+
+					if len(results) == 0 {
+						displayText.WriteString("No matching keys found.\n")
+					} else {
+						for key, value := range results {
+							ttl, _ := conn.GetTTL(key)
+							displayText.WriteString(fmt.Sprintf("[yellow]Key:[white] %s\n", key))
+							if node, err := conn.KeyNode(key); err == nil && node != "" {
+								displayText.WriteString(fmt.Sprintf("[yellow]Node:[white] %s\n", node))
+							}
+							displayText.WriteString(fmt.Sprintf("[yellow]Value:[white] %s\n", value))
+							displayText.WriteString(fmt.Sprintf("[yellow]TTL:[white] %v\n\n", ttl))
+						}
+					}
 
-			results, err := ExecuteQuery(redis, condition)
-			if err != nil {
-				logDisplay.Write([]byte(fmt.Sprintf("[red]Query Error:[white] %v\n", err)))
-				cmdInput.SetText("")
-				return
+					return func() {
+						kvDisplay.Clear()
+						kvDisplay.SetText(displayText.String()).SetTextAlign(tview.AlignLeft)
+					}
+				})
 			}
 
-			// Display results
-			var displayText strings.Builder
-			displayText.WriteString("[green]Query Results:[white]\n\n")
-
-			if len(results) == 0 {
-				displayText.WriteString("No matching keys found.\n")
+			// If a different connection is specified, connect to it first
+			if condition.ConnectionName != "" {
+				connectSaved(condition.ConnectionName, func(ConnectionConfig) { run() })
 			} else {
-				for key, value := range results {
-					ttl, _ := redis.GetTTL(key)
-					displayText.WriteString(fmt.Sprintf("[yellow]Key:[white] %s\n", key))
-					displayText.WriteString(fmt.Sprintf("[yellow]Value:[white] %s\n", value))
-					displayText.WriteString(fmt.Sprintf("[yellow]TTL:[white] %v\n\n", ttl))
-				}
+				run()
 			}
-
-			kvDisplay.Clear()
-			kvDisplay.SetText(displayText.String()).SetTextAlign(tview.AlignLeft)
 			cmdInput.SetText("")
 			return
 		case strings.HasPrefix(cmd, "update"):
@@ -351,33 +463,31 @@ func Win3(app *tview.Application, logDisplay *tview.TextView, redis *utils.Redis
 				return
 			}
 
-			// If a different connection is specified, connect to it
-			if updateQuery.ConnectionName != "" {
-				config, err := FindConnectionByName(updateQuery.ConnectionName)
-				if err != nil {
-					logDisplay.Write([]byte(fmt.Sprintf("[red]Connection Error:[white] %v\n", err)))
-					cmdInput.SetText("")
-					return
-				}
+			// Execute the update
+			run := func() {
+				runner.Start("update", func(conn *utils.RedisConnection) func() {
+					updatedCount, err := ExecuteUpdateQuery(conn, updateQuery)
+					return func() {
+						if isCancelled(err) {
+							logDisplay.Write([]byte(fmt.Sprintf("[yellow]Update cancelled after %d keys[white]\n", updatedCount)))
+							return
+						} else if err != nil {
+							logDisplay.Write([]byte(fmt.Sprintf("[red]Update Error:[white] %v\n", err)))
+							return
+						}
 
-				err = redis.Connect(config.Host, config.Port)
-				if err != nil {
-					logDisplay.Write([]byte(fmt.Sprintf("[red]Connection Error:[white] %v\n", err)))
-					cmdInput.SetText("")
-					return
-				}
+						logDisplay.Write([]byte(fmt.Sprintf("[green]Successfully updated %d keys[white]\n", updatedCount)))
+						RefreshData(logDisplay, kvDisplay, redis)
+					}
+				})
 			}
 
-			// Execute the update
-			updatedCount, err := ExecuteUpdateQuery(redis, updateQuery)
-			if err != nil {
-				logDisplay.Write([]byte(fmt.Sprintf("[red]Update Error:[white] %v\n", err)))
-				cmdInput.SetText("")
-				return
+			// If a different connection is specified, connect to it first
+			if updateQuery.ConnectionName != "" {
+				connectSaved(updateQuery.ConnectionName, func(ConnectionConfig) { run() })
+			} else {
+				run()
 			}
-
-			logDisplay.Write([]byte(fmt.Sprintf("[green]Successfully updated %d keys[white]\n", updatedCount)))
-			RefreshData(logDisplay, kvDisplay, redis)
 			cmdInput.SetText("")
 			return
 		case strings.HasPrefix(cmd, "del from"):
@@ -388,50 +498,54 @@ func Win3(app *tview.Application, logDisplay *tview.TextView, redis *utils.Redis
 				return
 			}
 
-			// If a different connection is specified, connect to it
-			if deleteQuery.ConnectionName != "" {
-				config, err := FindConnectionByName(deleteQuery.ConnectionName)
-				if err != nil {
-					logDisplay.Write([]byte(fmt.Sprintf("[red]Connection Error:[white] %v\n", err)))
-					cmdInput.SetText("")
-					return
-				}
-
-				err = redis.Connect(config.Host, config.Port)
-				if err != nil {
-					logDisplay.Write([]byte(fmt.Sprintf("[red]Connection Error:[white] %v\n", err)))
-					cmdInput.SetText("")
-					return
-				}
-			}
-
 			// Get confirmation function and matched keys
-			confirmFunc, matchedKeys, err := ExecuteDeleteQuery(redis, deleteQuery)
-			if err != nil {
-				logDisplay.Write([]byte(fmt.Sprintf("[red]Delete Error:[white] %v\n", err)))
-				cmdInput.SetText("")
-				return
-			}
-
-			// Show confirmation modal with matched keys
-			keysList := strings.Join(matchedKeys, "\n")
-			modal := tview.NewModal().
-				SetText(fmt.Sprintf("Are you sure you want to delete these %d keys?\n\n%s", len(matchedKeys), keysList)).
-				AddButtons([]string{"Yes", "No"}).
-				SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-					if buttonLabel == "Yes" {
-						deletedCount, err := confirmFunc()
-						if err != nil {
+			run := func() {
+				runner.Start("delete query", func(conn *utils.RedisConnection) func() {
+					confirmFunc, matchedKeys, err := ExecuteDeleteQuery(conn, deleteQuery)
+					return func() {
+						if isCancelled(err) {
+							return
+						} else if err != nil {
 							logDisplay.Write([]byte(fmt.Sprintf("[red]Delete Error:[white] %v\n", err)))
-						} else {
-							logDisplay.Write([]byte(fmt.Sprintf("[green]Successfully deleted %d keys[white]\n", deletedCount)))
+							return
 						}
-						RefreshData(logDisplay, kvDisplay, redis)
+
+						// Show confirmation modal with matched keys
+						keysList := strings.Join(matchedKeys, "\n")
+						modal := tview.NewModal().
+							SetText(fmt.Sprintf("Are you sure you want to delete these %d keys?\n\n%s", len(matchedKeys), keysList)).
+							AddButtons([]string{"Yes", "No"}).
+							SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+								app.SetRoot(mainFlex, true)
+								if buttonLabel == "Yes" {
+									runner.Start("delete", func(conn *utils.RedisConnection) func() {
+										deletedCount, err := confirmFunc(conn)
+										return func() {
+											if isCancelled(err) {
+												logDisplay.Write([]byte(fmt.Sprintf("[yellow]Delete cancelled after %d keys[white]\n", deletedCount)))
+											} else if err != nil {
+												logDisplay.Write([]byte(fmt.Sprintf("[red]Delete Error:[white] %v\n", err)))
+											} else {
+												logDisplay.Write([]byte(fmt.Sprintf("[green]Successfully deleted %d keys[white]\n", deletedCount)))
+											}
+											RefreshData(logDisplay, kvDisplay, redis)
+										}
+									})
+								}
+								cmdInput.SetText("")
+							})
+						app.SetRoot(modal, false)
 					}
-					app.SetRoot(mainFlex, true)
-					cmdInput.SetText("")
 				})
-			app.SetRoot(modal, false)
+			}
+
+			// If a different connection is specified, connect to it first
+			if deleteQuery.ConnectionName != "" {
+				connectSaved(deleteQuery.ConnectionName, func(ConnectionConfig) { run() })
+			} else {
+				run()
+			}
+			cmdInput.SetText("")
 			return
 		case cmd == "flushall":
 			// Add confirmation dialog
@@ -488,13 +602,18 @@ func Win3(app *tview.Application, logDisplay *tview.TextView, redis *utils.Redis
 			cmdInput.SetText("")
 			return
 		case cmd == "summary":
-			stats, err := redis.GetStats()
-			if err != nil {
-				logDisplay.Write([]byte(fmt.Sprintf("[red]Error getting Redis stats:[white] %v\n", err)))
-				cmdInput.SetText("")
-				return
-			}
-			DisplaySummary(kvDisplay, stats)
+			runner.Start("summary", func(conn *utils.RedisConnection) func() {
+				stats, err := conn.GetStats()
+				return func() {
+					if isCancelled(err) {
+						return
+					} else if err != nil {
+						logDisplay.Write([]byte(fmt.Sprintf("[red]Error getting Redis stats:[white] %v\n", err)))
+						return
+					}
+					DisplaySummary(kvDisplay, stats)
+				}
+			})
 			cmdInput.SetText("")
 			return
 		case cmd == "key filter update":
@@ -524,7 +643,7 @@ func Win3(app *tview.Application, logDisplay *tview.TextView, redis *utils.Redis
 			return
 
 		case cmd == "export":
-			form := ExportForm(app, redis, kvDisplay, logDisplay, cmdFlex, formContainer, suggestionDisplay, cmdInput)
+			form := ExportForm(app, redis, runner, kvDisplay, logDisplay, cmdFlex, formContainer, suggestionDisplay, cmdInput)
 			formContainer.Clear()
 			cmdFlex.Clear()
 			formContainer.AddItem(form, 0, 1, true)
@@ -534,6 +653,45 @@ func Win3(app *tview.Application, logDisplay *tview.TextView, redis *utils.Redis
 			cmdInput.SetText("")
 			return
 
+		case cmd == "use db":
+			runner.Start("database list", func(conn *utils.RedisConnection) func() {
+				dbs, err := conn.Databases()
+				return func() {
+					if isCancelled(err) {
+						return
+					} else if err != nil {
+						logDisplay.Write([]byte(fmt.Sprintf("[red]Error reading databases: %v[white]\n", err)))
+						return
+					}
+					picker := DatabasePicker(app, redis, runner, dbs, kvDisplay, logDisplay, cmdFlex, formContainer, suggestionDisplay, cmdInput)
+					formContainer.Clear()
+					cmdFlex.Clear()
+					formContainer.AddItem(picker, 0, 1, true)
+					cmdFlex.AddItem(formContainer, 0, 1, false)
+					cmdFlex.AddItem(suggestionDisplay, 3, 0, false)
+					cmdFlex.AddItem(cmdInput, 1, 0, true)
+					app.SetFocus(picker)
+				}
+			})
+			cmdInput.SetText("")
+			return
+
+		case strings.HasPrefix(cmd, "use db "):
+			index, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(cmd, "use db ")))
+			if err != nil {
+				logDisplay.Write([]byte("[red]Usage:[white] use db <number>\n"))
+				cmdInput.SetText("")
+				return
+			}
+			runner.Start("use db", func(conn *utils.RedisConnection) func() {
+				err := conn.SelectDB(index)
+				return func() {
+					logSelectDB(logDisplay, index, err)
+				}
+			})
+			cmdInput.SetText("")
+			return
+
 		case cmd == "help":
 			cmdFlex.Clear()
 			DisplayHelp(kvDisplay)
@@ -576,23 +734,17 @@ func Win3(app *tview.Application, logDisplay *tview.TextView, redis *utils.Redis
 
 		case strings.HasPrefix(cmd, "connect "):
 			connectionName := strings.TrimSpace(strings.TrimPrefix(cmd, "connect"))
-			config, err := FindConnectionByName(connectionName)
-			if err != nil {
-				logDisplay.Write([]byte(fmt.Sprintf("[red]%v[white]\n", err)))
-				cmdInput.SetText("")
-				return
-			}
-
-			err = redis.Connect(config.Host, config.Port)
-			if err != nil {
-				logDisplay.Write([]byte(fmt.Sprintf("[red]Connection failed: %v[white]\n", err)))
-				cmdInput.SetText("")
-			} else {
-				logDisplay.Write([]byte(fmt.Sprintf("[green]Connected to '%s' at %s:%s[white]\n",
-					config.Name, config.Host, config.Port)))
+			connectSaved(connectionName, func(config ConnectionConfig) {
+				if config.Type == utils.ConnectionSentinel {
+					logDisplay.Write([]byte(fmt.Sprintf("[green]Connected to '%s' via sentinel master '%s'[white]\n",
+						config.Name, config.MasterName)))
+				} else {
+					logDisplay.Write([]byte(fmt.Sprintf("[green]Connected to '%s' at %s:%s[white]\n",
+						config.Name, config.Host, config.Port)))
+				}
 				RefreshData(logDisplay, kvDisplay, redis)
-				cmdInput.SetText("")
-			}
+			})
+			cmdInput.SetText("")
 			return
 		case strings.HasPrefix(cmd, "del connection "):
 			connectionName := strings.TrimSpace(strings.TrimPrefix(cmd, "del connection "))
@@ -632,17 +784,24 @@ func Win3(app *tview.Application, logDisplay *tview.TextView, redis *utils.Redis
 			return
 
 		default:
-			result, err := redis.ExecuteCommand(cmd)
-			if err != nil {
-				logDisplay.Write([]byte(fmt.Sprintf("[red]Error:[white] %v\n", err)))
-			} else {
-				logDisplay.Write([]byte(fmt.Sprintf("[green]Result:[white] %v\n", result)))
-			}
+			runner.Start("command", func(conn *utils.RedisConnection) func() {
+				result, err := conn.ExecuteCommand(cmd)
+				return func() {
+					if isCancelled(err) {
+						return
+					} else if errors.Is(err, utils.ErrAuthRequired) || errors.Is(err, utils.ErrWrongPassword) {
+						logDisplay.Write([]byte(fmt.Sprintf("[red]%v[white]\n", err)))
+					} else if err != nil {
+						logDisplay.Write([]byte(fmt.Sprintf("[red]Error:[white] %v\n", err)))
+					} else {
+						logDisplay.Write([]byte(fmt.Sprintf("[green]Result:[white] %v\n", result)))
+					}
+					RefreshData(logDisplay, kvDisplay, redis)
+				}
+			})
 			cmdInput.SetText("")
+			return
 		}
-
-		cmdInput.SetText("")
-		RefreshData(logDisplay, kvDisplay, redis)
 	})
 
 	// Add suggestion display and command input to the flex container
@@ -650,5 +809,18 @@ func Win3(app *tview.Application, logDisplay *tview.TextView, redis *utils.Redis
 	cmdFlex.AddItem(suggestionDisplay, 3, 0, false)
 	cmdFlex.AddItem(cmdInput, 1, 0, true)
 
-	return cmdFlex, kvDisplay, cmdInput, mainFlex
+	return cmdFlex, kvDisplay, cmdInput, mainFlex, views
+}
+
+// openHint points get output at the editor for types that have one
+func openHint(kind string) string {
+	switch kind {
+	case "hash":
+		return ", or 'open' to edit its fields"
+	case "list", "set", "zset":
+		return ", or 'open' to edit its elements"
+	case "stream":
+		return ", or 'open' to browse its entries and consumer groups"
+	}
+	return ""
 }
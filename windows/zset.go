@@ -0,0 +1,283 @@
+package windows
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Amrit02102004/RediCLI/utils"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// formatScore renders a score with no more digits than it needs
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'g', -1, 64)
+}
+
+// validScoreBound checks a ZRANGEBYSCORE bound: a number, optionally
+// preceded by "(" to exclude it, or -inf / +inf
+func validScoreBound(bound string) bool {
+	value := strings.TrimPrefix(bound, "(")
+	switch strings.ToLower(value) {
+	case "-inf", "+inf", "inf":
+		return true
+	}
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil
+}
+
+// ZSetEditor lists a sorted set in score order, a page at a time and
+// optionally limited to a score range, with actions to add members (ZADD),
+// change or increment their score (ZINCRBY) and remove them (ZREM). Redis
+// is read and written through runner, so slow calls can be cancelled.
+func ZSetEditor(app *tview.Application, runner *OperationRunner, logDisplay *tview.TextView, pane *DisplayPane, key string) {
+	view := newCollectionView(app, pane, logDisplay, "Sorted set "+tview.Escape(key),
+		"[gray]a add · Enter/e score · + increment · x remove · / score range · s order · n/p page · r reload · Esc close[white]")
+
+	var page *utils.ZSetPage
+	min, max := "-inf", "+inf"
+	offset := int64(0)
+	desc := false
+
+	render := func() {
+		view.table.Clear()
+		view.setColumns("#", "Score", "Member")
+		for i, m := range page.Members {
+			view.table.SetCell(i+1, 0, tview.NewTableCell(strconv.FormatInt(page.Offset+int64(i), 10)).
+				SetTextColor(tcell.ColorGray).
+				SetAlign(tview.AlignRight))
+			view.table.SetCell(i+1, 1, tview.NewTableCell(formatScore(m.Score)).
+				SetTextColor(tcell.ColorGreen).
+				SetAlign(tview.AlignRight))
+			view.table.SetCell(i+1, 2, tview.NewTableCell(cellText(m.Member)).
+				SetMaxWidth(80).
+				SetExpansion(1))
+		}
+
+		order := "lowest"
+		if desc {
+			order = "highest"
+		}
+		info := fmt.Sprintf("[yellow]%d members[white] · %s score first", page.Length, order)
+		if min != "-inf" || max != "+inf" {
+			info += fmt.Sprintf(" · [green]%d[white] with scores %s to %s", page.Matched, tview.Escape(min), tview.Escape(max))
+		}
+		if len(page.Members) > 0 {
+			info += fmt.Sprintf(" · showing %d-%d", page.Offset, page.Offset+int64(len(page.Members))-1)
+		}
+		if page.Length == 0 {
+			info += " · [red]the key no longer exists[white]"
+		}
+		view.header.SetText(info)
+	}
+
+	// load reads the page at offset in the background, keeping the selected
+	// row, then calls then (when set) with the outcome
+	load := func(then func(err error)) {
+		min, max, from, desc := min, max, offset, desc
+		runner.Start("sorted set page", func(conn *utils.RedisConnection) func() {
+			next, err := conn.ZSetRange(key, min, max, from, desc)
+			if err == nil && len(next.Members) == 0 && from > 0 && next.Matched > 0 {
+				// Members were removed past this page, show the last page instead
+				from = (next.Matched - 1) / utils.ZSetPageSize * utils.ZSetPageSize
+				next, err = conn.ZSetRange(key, min, max, from, desc)
+			}
+			return func() {
+				if err == nil {
+					row, _ := view.table.GetSelection()
+					offset, page = from, next
+					render()
+					view.restoreSelection(row)
+					view.footer.SetText(view.hints)
+				} else if !isCancelled(err) {
+					view.showError(err)
+				}
+				if then != nil {
+					then(err)
+				}
+			}
+		})
+	}
+
+	selectedMember := func() (utils.ZMember, bool) {
+		i := view.selected(len(page.Members))
+		if i < 0 {
+			return utils.ZMember{}, false
+		}
+		return page.Members[i], true
+	}
+
+	// showMemberForm adds a member, or changes the score of existing
+	showMemberForm := func(existing *utils.ZMember) {
+		form := tview.NewForm()
+		memberInput := tview.NewInputField().SetLabel("Member: ").SetFieldWidth(40)
+		scoreInput := tview.NewInputField().
+			SetLabel("Score: ").
+			SetFieldWidth(20).
+			SetAcceptanceFunc(tview.InputFieldFloat)
+		if existing != nil {
+			form.SetTitle(fmt.Sprintf(" Score of %s ", cellText(existing.Member)))
+			memberInput.SetText(existing.Member).SetDisabled(true)
+			scoreInput.SetText(formatScore(existing.Score))
+		} else {
+			form.SetTitle(" Add member ")
+			scoreInput.SetText("0")
+		}
+		form.SetBorder(true)
+		form.AddFormItem(memberInput)
+		form.AddFormItem(scoreInput)
+
+		form.AddButton("Save", func() {
+			member := memberInput.GetText()
+			score, err := strconv.ParseFloat(scoreInput.GetText(), 64)
+			if err != nil {
+				view.log("[red]Error:[white] invalid score '%s'", tview.Escape(scoreInput.GetText()))
+				return
+			}
+
+			runner.Start("save member", func(conn *utils.RedisConnection) func() {
+				added, err := conn.SetZSetMember(key, member, score, existing == nil)
+				return func() {
+					if isCancelled(err) {
+						return
+					} else if err != nil {
+						view.log("[red]Error saving member:[white] %v", err)
+						return
+					}
+					if existing == nil && !added {
+						view.log("[red]Error:[white] '%s' is already a member, change its score instead", cellText(member))
+						return
+					}
+					view.log("[green]Set the score of '%s' in '%s' to %s[white]", cellText(member), tview.Escape(key), formatScore(score))
+					view.closeOverlay()
+					load(nil)
+				}
+			})
+		})
+		form.AddButton("Cancel", view.closeOverlay)
+		view.showForm(form, 60, 9)
+	}
+
+	increment := func() {
+		m, ok := selectedMember()
+		if !ok {
+			return
+		}
+		view.prompt(fmt.Sprintf("Add to the score of %s: ", cellText(m.Member)), "1", func(text string) {
+			by, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+			if err != nil {
+				view.showError(fmt.Errorf("invalid increment '%s'", text))
+				return
+			}
+			runner.Start("increment", func(conn *utils.RedisConnection) func() {
+				score, err := conn.IncrZSetMember(key, m.Member, by)
+				return func() {
+					if isCancelled(err) {
+						return
+					} else if err != nil {
+						view.showError(err)
+						return
+					}
+					view.log("[green]Score of '%s' in '%s' is now %s[white]", cellText(m.Member), tview.Escape(key), formatScore(score))
+					load(nil)
+				}
+			})
+		})
+	}
+
+	removeMember := func() {
+		m, ok := selectedMember()
+		if !ok {
+			return
+		}
+		question := fmt.Sprintf("Remove '%s' from '%s'?", m.Member, key)
+		if page.Length == 1 {
+			question += "\n\nIt is the last member, so the key will be deleted too."
+		}
+		view.confirm(question, func() {
+			runner.Start("remove member", func(conn *utils.RedisConnection) func() {
+				err := conn.RemoveZSetMember(key, m.Member)
+				return func() {
+					if isCancelled(err) {
+						return
+					} else if err != nil {
+						view.log("[red]Error removing member:[white] %v", err)
+						return
+					}
+					view.log("[green]Removed '%s' from '%s'[white]", cellText(m.Member), tview.Escape(key))
+					load(nil)
+				}
+			})
+		})
+	}
+
+	view.table.SetSelectedFunc(func(row, column int) {
+		if m, ok := selectedMember(); ok {
+			showMemberForm(&m)
+		}
+	})
+
+	// The view opens once the first page is in
+	onKey := func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyDelete {
+			removeMember()
+			return nil
+		}
+
+		switch event.Rune() {
+		case 'a':
+			showMemberForm(nil)
+		case 'e':
+			if m, ok := selectedMember(); ok {
+				showMemberForm(&m)
+			}
+		case '+':
+			increment()
+		case 'x':
+			removeMember()
+		case 's':
+			desc = !desc
+			offset = 0
+			load(nil)
+		case 'n':
+			if offset+utils.ZSetPageSize < page.Matched {
+				offset += utils.ZSetPageSize
+				load(nil)
+			}
+		case 'p':
+			if offset > 0 {
+				offset -= utils.ZSetPageSize
+				load(nil)
+			}
+		case 'r':
+			load(nil)
+		case '/':
+			view.prompt("Score range (min max, ( excludes, -inf/+inf): ", min+" "+max, func(text string) {
+				bounds := strings.Fields(text)
+				if len(bounds) == 0 {
+					bounds = []string{"-inf", "+inf"}
+				}
+				if len(bounds) != 2 || !validScoreBound(bounds[0]) || !validScoreBound(bounds[1]) {
+					view.showError(fmt.Errorf("invalid score range '%s', use e.g. 10 (20 or -inf +inf", text))
+					return
+				}
+				min, max = bounds[0], bounds[1]
+				offset = 0
+				load(nil)
+			})
+		default:
+			return event
+		}
+		return nil
+	}
+	load(func(err error) {
+		if err != nil {
+			if !isCancelled(err) {
+				view.log("[red]Error reading sorted set '%s':[white] %v", tview.Escape(key), err)
+			}
+			return
+		}
+		view.open(onKey)
+	})
+}